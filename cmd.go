@@ -31,6 +31,7 @@ package golang_utils
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 type CmdFunc func(cmd *cobra.Command, args []string)
@@ -39,7 +40,12 @@ type CmdConfig struct {
 	use            string
 	short          string
 	long           string
+	example        string
 	aliases        []string
+	groupID        string
+	groups         []*cobra.Group
+	hidden         bool
+	deprecated     string
 	pre            CmdFunc
 	run            CmdFunc
 	post           CmdFunc
@@ -48,6 +54,22 @@ type CmdConfig struct {
 	args           cobra.PositionalArgs
 	enableTracking bool
 	version        string
+	subCommands    []*CmdConfig
+	flags          []func(flags *pflag.FlagSet)
+	pFlags         []func(flags *pflag.FlagSet)
+	argCompletions []CompletionProvider
+	flagCompletion map[string]CompletionProvider
+	requiredFlags  []string
+	prompting      bool
+	flagPrompts    map[string]*Prompt
+	argPrompts     []*Prompt
+	middleware     []Middleware
+	flagConfigKeys map[string]string
+	flagEnvVars    map[string]string
+	hiddenFlags    []string
+	// Deprecation messages of flags and of their shorthands, keyed by flag name
+	deprecatedFlags      map[string]string
+	deprecatedShorthands map[string]string
 }
 
 func CommandBuilder(use string) *CmdConfig {
@@ -67,6 +89,11 @@ func (cc *CmdConfig) SetLongDescription(desc string) *CmdConfig {
 	return cc
 }
 
+func (cc *CmdConfig) SetExample(example string) *CmdConfig {
+	cc.example = example
+	return cc
+}
+
 func (cc *CmdConfig) SetAliases(alias ...string) *CmdConfig {
 	cc.aliases = alias
 	return cc
@@ -102,11 +129,147 @@ func (cc *CmdConfig) SetArgValidations(argValidations cobra.PositionalArgs) *Cmd
 	return cc
 }
 
+// AddFlags registers a function that declares the command's local flags when it is built.
+func (cc *CmdConfig) AddFlags(flagFunc func(flags *pflag.FlagSet)) *CmdConfig {
+	cc.flags = append(cc.flags, flagFunc)
+	return cc
+}
+
+// AddPersistentFlags registers a function that declares flags shared with all sub commands.
+func (cc *CmdConfig) AddPersistentFlags(flagFunc func(flags *pflag.FlagSet)) *CmdConfig {
+	cc.pFlags = append(cc.pFlags, flagFunc)
+	return cc
+}
+
+// SetArgCompletions sets the shell completion providers for positional args. The provider at index i
+// completes the i-th arg and the last provider completes any args after that.
+func (cc *CmdConfig) SetArgCompletions(providers ...CompletionProvider) *CmdConfig {
+	cc.argCompletions = providers
+	return cc
+}
+
+// SetFlagCompletion sets the shell completion provider for the value of the named flag.
+func (cc *CmdConfig) SetFlagCompletion(flagName string, provider CompletionProvider) *CmdConfig {
+	if cc.flagCompletion == nil {
+		cc.flagCompletion = make(map[string]CompletionProvider)
+	}
+	cc.flagCompletion[flagName] = provider
+	return cc
+}
+
+// SetFlagConfigKey records the configuration property the named flag sets so that generated docs
+// can show it.
+func (cc *CmdConfig) SetFlagConfigKey(flagName string, configKey string) *CmdConfig {
+	if cc.flagConfigKeys == nil {
+		cc.flagConfigKeys = make(map[string]string)
+	}
+	cc.flagConfigKeys[flagName] = configKey
+	return cc
+}
+
+// SetFlagEnvVar records the environment variable that can be used in place of the named flag.
+func (cc *CmdConfig) SetFlagEnvVar(flagName string, envVar string) *CmdConfig {
+	if cc.flagEnvVars == nil {
+		cc.flagEnvVars = make(map[string]string)
+	}
+	cc.flagEnvVars[flagName] = envVar
+	return cc
+}
+
+// SetFlagsHidden hides the named flags from help and docs. They still work when given.
+func (cc *CmdConfig) SetFlagsHidden(flagNames ...string) *CmdConfig {
+	cc.hiddenFlags = append(cc.hiddenFlags, flagNames...)
+	return cc
+}
+
+// SetFlagDeprecated marks the named flag as deprecated, hiding it from help. The message is printed
+// whenever the flag is used.
+func (cc *CmdConfig) SetFlagDeprecated(flagName string, msg string) *CmdConfig {
+	if cc.deprecatedFlags == nil {
+		cc.deprecatedFlags = make(map[string]string)
+	}
+	cc.deprecatedFlags[flagName] = msg
+	return cc
+}
+
+// SetFlagShorthandDeprecated marks the shorthand of the named flag as deprecated. The message is
+// printed whenever the shorthand is used, the long form carries on as before.
+func (cc *CmdConfig) SetFlagShorthandDeprecated(flagName string, msg string) *CmdConfig {
+	if cc.deprecatedShorthands == nil {
+		cc.deprecatedShorthands = make(map[string]string)
+	}
+	cc.deprecatedShorthands[flagName] = msg
+	return cc
+}
+
+func (cc *CmdConfig) SetRequiredFlags(flagNames ...string) *CmdConfig {
+	cc.requiredFlags = append(cc.requiredFlags, flagNames...)
+	return cc
+}
+
+// EnablePrompting asks for missing required flags and args when running in a terminal.
+// Non-interactive runs still fail with the usual errors.
+func (cc *CmdConfig) EnablePrompting() *CmdConfig {
+	cc.prompting = true
+	return cc
+}
+
+// SetFlagPrompt customizes the prompt used when the required flag [flagName] is missing.
+// Without one the prompt is derived from the flag's name and usage.
+func (cc *CmdConfig) SetFlagPrompt(flagName string, prompt *Prompt) *CmdConfig {
+	if cc.flagPrompts == nil {
+		cc.flagPrompts = make(map[string]*Prompt)
+	}
+	cc.flagPrompts[flagName] = prompt
+	cc.prompting = true
+	return cc
+}
+
+// SetArgPrompts sets the prompts, in order, for positional args missing from the command line.
+func (cc *CmdConfig) SetArgPrompts(prompts ...*Prompt) *CmdConfig {
+	cc.argPrompts = prompts
+	cc.prompting = true
+	return cc
+}
+
 func (cc *CmdConfig) SetVersion(version string) *CmdConfig {
 	cc.version = version
 	return cc
 }
 
+// SetGroup places the command under the help group [groupID] declared on its parent with AddGroup.
+func (cc *CmdConfig) SetGroup(groupID string) *CmdConfig {
+	cc.groupID = groupID
+	return cc
+}
+
+// AddGroup declares a help group that sub commands can be placed under via SetGroup.
+func (cc *CmdConfig) AddGroup(groupID string, title string) *CmdConfig {
+	cc.groups = append(cc.groups, &cobra.Group{ID: groupID, Title: title})
+	return cc
+}
+
+func (cc *CmdConfig) SetHidden(hidden bool) *CmdConfig {
+	cc.hidden = hidden
+	return cc
+}
+
+// SetDeprecated marks the command as deprecated. The message is printed whenever the command is used.
+func (cc *CmdConfig) SetDeprecated(msg string) *CmdConfig {
+	cc.deprecated = msg
+	return cc
+}
+
+// AddSubCommands registers child commands. They are built along with this command when Build is called.
+func (cc *CmdConfig) AddSubCommands(subCommands ...*CmdConfig) *CmdConfig {
+	for _, sub := range subCommands {
+		if sub != nil {
+			cc.subCommands = append(cc.subCommands, sub)
+		}
+	}
+	return cc
+}
+
 func (cc *CmdConfig) DisableTracking() *CmdConfig {
 	cc.enableTracking = false
 	return cc
@@ -117,6 +280,8 @@ func (cc *CmdConfig) EnableTracking() *CmdConfig {
 	return cc
 }
 
+// Build creates the cobra command tree. Run it with Execute, or CmdConfig.Execute, to have SIGINT
+// and SIGTERM stop it gracefully and the State shutdown once it is done.
 func (cc *CmdConfig) Build() *cobra.Command {
 	return newCommand(cc)
 }
@@ -126,7 +291,12 @@ func newCommand(config *CmdConfig) *cobra.Command {
 	newCmd := &cobra.Command{
 		Use:               config.use,
 		Short:             config.short,
+		Long:              config.long,
+		Example:           config.example,
 		Aliases:           config.aliases,
+		GroupID:           config.groupID,
+		Hidden:            config.hidden,
+		Deprecated:        config.deprecated,
 		Args:              config.args,
 		PreRun:            config.pre,
 		PersistentPreRun:  config.pPre,
@@ -136,20 +306,91 @@ func newCommand(config *CmdConfig) *cobra.Command {
 		Version:           config.version,
 	}
 
-	if config.enableTracking {
-		if config.pre != nil {
-			newCmd.PreRun = func(cmd *cobra.Command, args []string) {
-				CurrentState().TrackCmd(cmd, args)
-				config.pre(cmd, args)
-			}
-		} else {
-			newCmd.PreRun = CurrentState().TrackCmd
+	if config.run != nil {
+		middleware := config.middleware
+		if config.enableTracking {
+			middleware = append([]Middleware{TrackingMiddleware()}, middleware...)
 		}
+		// The middleware wraps PreRun and PostRun too, so they see the tracked command and run while
+		// the State is Running.
+		newCmd.PreRun, newCmd.PostRun = nil, nil
+		newCmd.Run = chainMiddleware(withRunHooks(config.pre, config.run, config.post), middleware)
+	}
+
+	for _, flagFunc := range config.flags {
+		flagFunc(newCmd.Flags())
+	}
+	for _, flagFunc := range config.pFlags {
+		flagFunc(newCmd.PersistentFlags())
+	}
+	for _, flagName := range config.requiredFlags {
+		MakeFlagRequired(newCmd, flagName)
+	}
+	for flagName, configKey := range config.flagConfigKeys {
+		AnnotateFlag(newCmd, flagName, CONFIG_KEY_ANNOTATION, configKey)
+	}
+	for flagName, envVar := range config.flagEnvVars {
+		AnnotateFlag(newCmd, flagName, ENV_VAR_ANNOTATION, envVar)
+	}
+	for _, flagName := range config.hiddenFlags {
+		CheckError(
+			cmdFlags(newCmd, flagName).MarkHidden(flagName), "Error hiding flag [%s] for the %s cmd", flagName, config.use,
+		)
+	}
+	for flagName, msg := range config.deprecatedFlags {
+		CheckError(
+			cmdFlags(newCmd, flagName).MarkDeprecated(flagName, msg),
+			"Error deprecating flag [%s] for the %s cmd", flagName, config.use,
+		)
+	}
+	for flagName, msg := range config.deprecatedShorthands {
+		CheckError(
+			cmdFlags(newCmd, flagName).MarkShorthandDeprecated(flagName, msg),
+			"Error deprecating the shorthand of flag [%s] for the %s cmd", flagName, config.use,
+		)
+	}
+
+	if config.prompting {
+		addPrompting(newCmd, config)
+	}
+
+	if len(config.argCompletions) > 0 {
+		newCmd.ValidArgsFunction = argCompletion(config.argCompletions)
+	}
+	for flagName, provider := range config.flagCompletion {
+		CheckError(
+			newCmd.RegisterFlagCompletionFunc(flagName, provider),
+			"Error registering completion for flag [%s] on the %s cmd",
+			flagName,
+			config.use,
+		)
+	}
+
+	newCmd.AddGroup(config.groups...)
+
+	for _, sub := range config.subCommands {
+		newCmd.AddCommand(sub.Build())
 	}
 
 	return newCmd
 }
 
+// withRunHooks runs pre and post, when set, either side of run.
+func withRunHooks(pre CmdFunc, run CmdFunc, post CmdFunc) CmdFunc {
+	if pre == nil && post == nil {
+		return run
+	}
+	return func(cmd *cobra.Command, args []string) {
+		if pre != nil {
+			pre(cmd, args)
+		}
+		run(cmd, args)
+		if post != nil {
+			post(cmd, args)
+		}
+	}
+}
+
 func MakeFlagRequired(cmd *cobra.Command, flagName string) {
 	CheckError(
 		cmd.MarkFlagRequired(flagName),
@@ -159,6 +400,25 @@ func MakeFlagRequired(cmd *cobra.Command, flagName string) {
 	)
 }
 
+// cmdFlags returns the flag set of cmd that declares the named flag, its local flags unless only
+// its persistent flags have it.
+func cmdFlags(cmd *cobra.Command, flagName string) *pflag.FlagSet {
+	if cmd.Flags().Lookup(flagName) == nil {
+		return cmd.PersistentFlags()
+	}
+	return cmd.Flags()
+}
+
+// AnnotateFlag sets an annotation on the named local or persistent flag of cmd.
+func AnnotateFlag(cmd *cobra.Command, flagName string, key string, value string) {
+	CheckError(
+		cmdFlags(cmd, flagName).SetAnnotation(flagName, key, []string{value}),
+		"Error annotating flag [%s] for the %s cmd",
+		flagName,
+		GetFullCmdName(cmd),
+	)
+}
+
 func GetFullCmdName(cmd *cobra.Command) string {
 	if cmd == nil {
 		return "????"
@@ -0,0 +1,98 @@
+package golang_utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPropagatesBuilderFields(t *testing.T) {
+	cmd := CommandBuilder("suzy").
+		SetShortDescription("short suzy").
+		SetLongDescription("long suzy").
+		SetExample("suzy --q").
+		SetAliases("sue").
+		SetHidden(true).
+		SetDeprecated("use suzyq").
+		SetVersion("1.0.0").
+		Build()
+
+	assert.Equal(t, "short suzy", cmd.Short)
+	assert.Equal(t, "long suzy", cmd.Long, "Long description should be copied into the command")
+	assert.Equal(t, "suzy --q", cmd.Example)
+	assert.Equal(t, []string{"sue"}, cmd.Aliases)
+	assert.True(t, cmd.Hidden)
+	assert.Equal(t, "use suzyq", cmd.Deprecated)
+	assert.Equal(t, "1.0.0", cmd.Version)
+}
+
+func TestBuildComposesSubCommandTree(t *testing.T) {
+	ran := ""
+	root := CommandBuilder("root").
+		DisableTracking().
+		AddGroup("mgmt", "Management Commands").
+		AddSubCommands(
+			CommandBuilder("parent").
+				DisableTracking().
+				SetGroup("mgmt").
+				AddSubCommands(
+					CommandBuilder("child").
+						DisableTracking().
+						SetRun(func(cmd *cobra.Command, args []string) { ran = GetFullCmdName(cmd) }),
+				),
+			nil,
+		).
+		Build()
+
+	assert.Equal(t, 1, len(root.Commands()), "nil sub commands should be ignored")
+	assert.True(t, root.ContainsGroup("mgmt"))
+
+	parent := root.Commands()[0]
+	assert.Equal(t, "mgmt", parent.GroupID)
+	assert.Equal(t, 1, len(parent.Commands()))
+
+	root.SetArgs([]string{"parent", "child"})
+	assert.NoError(t, root.Execute())
+	assert.Equal(t, "root.parent.child", ran)
+}
+
+func TestFlagsCanBeHiddenAndDeprecated(t *testing.T) {
+	build := func() *cobra.Command {
+		return CommandBuilder("suzy").
+			DisableTracking().
+			AddFlags(
+				func(flags *pflag.FlagSet) {
+					flags.Bool("secret-mode", false, "a hidden flag")
+					flags.String("old", "", "an old flag")
+					flags.StringP("color", "c", "", "the color")
+				},
+			).
+			SetFlagsHidden("secret-mode").
+			SetFlagDeprecated("old", "use --color instead").
+			SetFlagShorthandDeprecated("color", "use --color instead").
+			SetRun(func(*cobra.Command, []string) {}).
+			Build()
+	}
+
+	out := new(bytes.Buffer)
+	cmd := build()
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"--help"})
+	assert.NoError(t, cmd.Execute())
+	assert.NotContains(t, out.String(), "secret-mode")
+	assert.NotContains(t, out.String(), "--old")
+	assert.Contains(t, out.String(), "--color")
+	assert.NotContains(t, out.String(), "-c,", "the deprecated shorthand should not be shown")
+
+	out.Reset()
+	cmd = build()
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{"--secret-mode", "--old", "x", "-c", "red"})
+	assert.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "Flag --old has been deprecated, use --color instead")
+	assert.Contains(t, out.String(), "Flag shorthand -c has been deprecated, use --color instead")
+}
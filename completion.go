@@ -0,0 +1,200 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: completion.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// CompletionProvider - Supplies shell completion candidates for a positional argument or flag value
+type CompletionProvider func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// StaticCompletions completes from a fixed list of values.
+func StaticCompletions(values ...string) CompletionProvider {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterCompletions(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// FileGlobCompletions completes with the files matching any of the glob patterns (e.g. "*.yaml")
+// and the directories they may be in. Directories end with a "/" and leave the cursor after it so
+// completion can carry on inside them.
+func FileGlobCompletions(patterns ...string) CompletionProvider {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		matches := make([]string, 0)
+		// Keep what was typed up to the last / so matches extend it, ./ and all.
+		prefix := toComplete[:strings.LastIndex(toComplete, "/")+1]
+		dir := prefix
+		if dir == "" {
+			dir = "."
+		}
+		relative := func(path string) string {
+			return prefix + filepath.Base(path)
+		}
+		for _, pattern := range patterns {
+			found, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				LogError(err, "Invalid completion glob [%s]", pattern)
+				continue
+			}
+			for _, match := range found {
+				if match = relative(match); !Contains(matches, match) {
+					matches = append(matches, match)
+				}
+			}
+		}
+
+		directive := cobra.ShellCompDirectiveNoFileComp
+		showHidden := strings.HasPrefix(toComplete[len(prefix):], ".")
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				if !entry.IsDir() || (strings.HasPrefix(entry.Name(), ".") && !showHidden) {
+					continue
+				}
+				if subDir := relative(filepath.Join(dir, entry.Name())) + "/"; strings.HasPrefix(subDir, toComplete) {
+					matches = append(matches, subDir)
+					directive |= cobra.ShellCompDirectiveNoSpace
+				}
+			}
+		}
+		sort.Strings(matches)
+		return filterCompletions(matches, toComplete), directive
+	}
+}
+
+// ConfigListCompletions completes with the values of a list property (see Configuration.UpdateList).
+func ConfigListCompletions(propertyName string) CompletionProvider {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterCompletions(CurrentState().Config().GetList(propertyName), toComplete),
+			cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// ConfigMapKeyCompletions completes with the keys of a map property (see Configuration.UpdateMap).
+func ConfigMapKeyCompletions(propertyName string) CompletionProvider {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		keys := make([]string, 0)
+		for k := range CurrentState().Config().GetMap(propertyName) {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return filterCompletions(keys, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// ConfigKeyCompletions completes with every property name known to the configuration.
+func ConfigKeyCompletions() CompletionProvider {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		keys := CurrentState().Config().Keys()
+		sort.Strings(keys)
+		return filterCompletions(keys, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// ConfigProfileCompletions completes with the names of the profiles defined by the configuration.
+func ConfigProfileCompletions() CompletionProvider {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterCompletions(CurrentState().Config().Profiles(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CompletionCommandBuilder builds a `completion` command that emits bash, zsh and fish completion scripts.
+func CompletionCommandBuilder() *CmdConfig {
+	return CommandBuilder("completion").
+		SetShortDescription("Generate shell completion scripts").
+		SetLongDescription(
+			"Generate the completion script for the given shell. Load it in the current session with\n"+
+				"  source <(app completion bash)\n"+
+				"or write it to your shell's completion directory to load it in every session.",
+		).
+		AddSubCommands(
+			completionScriptBuilder(
+				"bash", func(cmd *cobra.Command) error {
+					return cmd.Root().GenBashCompletionV2(cmd.OutOrStdout(), true)
+				},
+			),
+			completionScriptBuilder(
+				"zsh", func(cmd *cobra.Command) error {
+					return cmd.Root().GenZshCompletion(cmd.OutOrStdout())
+				},
+			),
+			completionScriptBuilder(
+				"fish", func(cmd *cobra.Command) error {
+					return cmd.Root().GenFishCompletion(cmd.OutOrStdout(), true)
+				},
+			),
+		)
+}
+
+func completionScriptBuilder(shell string, gen func(cmd *cobra.Command) error) *CmdConfig {
+	return CommandBuilder(shell).
+		SetShortDescription(fmt.Sprintf("Generate the completion script for %s", shell)).
+		SetArgValidations(cobra.NoArgs).
+		DisableTracking().
+		SetRun(
+			func(cmd *cobra.Command, args []string) {
+				CheckError(gen(cmd), "Error generating %s completion script", shell)
+			},
+		)
+}
+
+// argCompletion dispatches to the provider registered for the position of the arg being completed.
+// The last provider is reused for any position beyond the registered providers.
+func argCompletion(providers []CompletionProvider) CompletionProvider {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(providers) == 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		position := len(args)
+		if position >= len(providers) {
+			position = len(providers) - 1
+		}
+		if providers[position] == nil {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return providers[position](cmd, args, toComplete)
+	}
+}
+
+func filterCompletions(values []string, toComplete string) []string {
+	filtered := make([]string, 0, len(values))
+	for _, value := range values {
+		if strings.HasPrefix(value, toComplete) {
+			filtered = append(filtered, value)
+		}
+	}
+	return filtered
+}
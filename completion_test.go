@@ -0,0 +1,101 @@
+package golang_utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCompletionTestRoot() *cobra.Command {
+	return CommandBuilder("suzy").
+		DisableTracking().
+		AddSubCommands(
+			CommandBuilder("q").
+				DisableTracking().
+				AddFlags(
+					func(flags *pflag.FlagSet) {
+						flags.String("color", "", "the color")
+					},
+				).
+				SetArgCompletions(StaticCompletions("apple", "apricot", "banana"), StaticCompletions("one", "two")).
+				SetFlagCompletion("color", StaticCompletions("red", "green")).
+				SetRun(func(*cobra.Command, []string) {}),
+		).
+		Build()
+}
+
+func complete(t *testing.T, root *cobra.Command, args ...string) []string {
+	out := new(bytes.Buffer)
+	root.SetOut(out)
+	root.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+	assert.NoError(t, root.Execute())
+
+	candidates := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if !strings.HasPrefix(line, ":") {
+			candidates = append(candidates, line)
+		}
+	}
+	return candidates
+}
+
+func TestArgCompletionsByPosition(t *testing.T) {
+	assert.Equal(t, []string{"apple", "apricot"}, complete(t, newCompletionTestRoot(), "q", "ap"))
+	assert.Equal(t, []string{"one", "two"}, complete(t, newCompletionTestRoot(), "q", "apple", ""))
+	assert.Equal(
+		t,
+		[]string{"two"},
+		complete(t, newCompletionTestRoot(), "q", "apple", "one", "t"),
+		"the last provider should complete any remaining args",
+	)
+}
+
+func TestFlagCompletion(t *testing.T) {
+	assert.Equal(t, []string{"green"}, complete(t, newCompletionTestRoot(), "q", "--color", "g"))
+}
+
+func TestCompletionCommandEmitsScripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		out := new(bytes.Buffer)
+		root := newStandardTestRoot()
+		root.SetOut(out)
+		root.SetArgs([]string{"completion", shell})
+		assert.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), "suzy", "%s completion script should reference the root command", shell)
+	}
+}
+
+func TestFileGlobCompletionsOfferDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"a.yaml", "b.txt", "conf/c.yaml", ".hidden/d.yaml"} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, path), nil, 0644))
+	}
+	complete := FileGlobCompletions("*.yaml")
+
+	completions, directive := complete(nil, nil, dir+"/")
+	assert.Equal(t, []string{dir + "/a.yaml", dir + "/conf/"}, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace, directive)
+
+	completions, directive = complete(nil, nil, dir+"/conf/")
+	assert.Equal(t, []string{dir + "/conf/c.yaml"}, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	completions, _ = complete(nil, nil, dir+"/.h")
+	assert.Equal(t, []string{dir + "/.hidden/"}, completions)
+
+	workDir, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer func() { assert.NoError(t, os.Chdir(workDir)) }()
+	completions, _ = complete(nil, nil, "./conf/")
+	assert.Equal(t, []string{"./conf/c.yaml"}, completions, "the ./ the user typed should be kept")
+	completions, _ = complete(nil, nil, "./")
+	assert.Equal(t, []string{"./a.yaml", "./conf/"}, completions)
+}
@@ -31,59 +31,137 @@ package golang_utils
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/apex/log"
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// Configuration - A configuration file and its properties. Each Configuration owns its own viper
+// instance so several can be loaded in the same process without clobbering each other.
 type Configuration struct {
+	v           *viper.Viper
+	lock        sync.RWMutex
 	filename    string
 	LoadedFrom  string
 	writeInHome bool
 	RunID       uint
 	defaults    Properties
+	file        string
+	envPrefix   string
+	layers      []*configLayer
+	flags       map[string]*pflag.Flag
+	// Where the key for encrypted values comes from, see SetSecretKeySource
+	secretKeyEnv  string
+	secretKeyFile string
+	migrations    []*ConfigMigration
+	backupCount   int
+	// The profile chosen with SetProfile and the one merged over the base settings
+	profile       string
+	profileSet    bool
+	activeProfile string
 }
 
 func NewConfiguration(filename string, writeInHome bool, defaults Properties) *Configuration {
 	return &Configuration{
+		v:           viper.New(),
 		filename:    filename,
 		writeInHome: writeInHome,
 		defaults:    defaults,
+		backupCount: DEFAULT_CONFIG_BACKUPS,
 	}
 }
 
+// clone returns a copy of the configuration, sharing its settings, so candidates loaded from it
+// decrypt, migrate, bind flags and apply defaults exactly like the original.
+func (c *Configuration) clone() *Configuration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return &Configuration{
+		v:             c.v,
+		filename:      c.filename,
+		LoadedFrom:    c.LoadedFrom,
+		writeInHome:   c.writeInHome,
+		RunID:         c.RunID,
+		defaults:      c.defaults,
+		file:          c.file,
+		envPrefix:     c.envPrefix,
+		layers:        c.layers,
+		flags:         c.flags,
+		secretKeyEnv:  c.secretKeyEnv,
+		secretKeyFile: c.secretKeyFile,
+		migrations:    c.migrations,
+		backupCount:   c.backupCount,
+		profile:       c.profile,
+		profileSet:    c.profileSet,
+		activeProfile: c.activeProfile,
+	}
+}
+
+// Viper returns the viper instance backing the configuration, e.g. for binding flags.
+func (c *Configuration) Viper() *viper.Viper {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.v
+}
+
+func (c *Configuration) setViper(v *viper.Viper) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.v = v
+}
+
+// Get returns the property as a string with any encrypted secret decrypted and any references
+// expanded, see Expand.
 func (c *Configuration) Get(propertyName string) string {
-	return viper.GetString(propertyName)
+	return cast.ToString(c.GetValue(propertyName))
+}
+
+// GetValue returns the property with encrypted secrets decrypted and references expanded, including
+// those in nested maps and lists.
+func (c *Configuration) GetValue(propertyName string) any {
+	return c.expandedValue(propertyName, c.Viper().Get(propertyName))
+}
+
+func (c *Configuration) IsSet(propertyName string) bool {
+	return c.Viper().IsSet(propertyName)
+}
+
+func (c *Configuration) Keys() []string {
+	return c.Viper().AllKeys()
 }
 
 func (c *Configuration) GetList(propertyName string) []string {
-	return viper.GetStringSlice(propertyName)
+	return cast.ToStringSlice(c.GetValue(propertyName))
 }
 
 func (c *Configuration) GetBool(propertyName string) bool {
-	return viper.GetBool(propertyName)
+	return cast.ToBool(c.GetValue(propertyName))
 }
 
 func (c *Configuration) GetFloat(propertyName string) float64 {
-	return viper.GetFloat64(propertyName)
+	return cast.ToFloat64(c.GetValue(propertyName))
 }
 
 func (c *Configuration) GetInt(propertyName string) int {
-	return viper.GetInt(propertyName)
+	return cast.ToInt(c.GetValue(propertyName))
 }
 
+// GetIntWithDefault returns the property, or defaultValue when it is unset. See GetOr.
 func (c *Configuration) GetIntWithDefault(propertyName string, defaultValue int) int {
-	val := viper.GetInt(propertyName)
-
-	if defaultValue != 0 && val == 0 {
-		return defaultValue
-	}
-	return val
+	return GetOr(c, propertyName, defaultValue)
 }
 
+// GetMap returns the map property with any encrypted secrets decrypted and references expanded.
 func (c *Configuration) GetMap(propertyName string) map[string]string {
-	return viper.GetStringMapString(propertyName)
+	return cast.ToStringMapString(c.GetValue(propertyName))
 }
 
 func (c *Configuration) HasResource(name string, property string) bool {
@@ -91,108 +169,180 @@ func (c *Configuration) HasResource(name string, property string) bool {
 }
 
 func (c *Configuration) Update(propertyName string, value string) {
-	viper.Set(propertyName, value)
-	c.Write("Error adding/updating config property [%s]", propertyName)
+	c.Viper().Set(propertyName, value)
+	CheckError(
+		c.writeEdit(yamlEdit{path: keyPath(propertyName), value: value}), "Error adding/updating config property [%s]",
+		propertyName,
+	)
 }
 
 func (c *Configuration) UpdateList(propertyName string, value string) {
-	current := c.GetList(propertyName)
+	current := cast.ToStringSlice(c.Viper().Get(propertyName)) // Keeps references unexpanded
 	if current == nil {
 		current = make([]string, 0)
 	}
-	if !Contains(current, value) {
-		current = append(current, value)
+	if Contains(current, value) {
+		return
 	}
-	viper.Set(propertyName, current)
-	c.Write("Error adding/updating config property [%s]", propertyName)
+	current = append(current, value)
+	c.Viper().Set(propertyName, current)
+	CheckError(
+		c.writeEdit(yamlEdit{path: keyPath(propertyName), value: current, append: true}),
+		"Error adding/updating config property [%s]", propertyName,
+	)
 }
 
 func (c *Configuration) UpdateMap(propertyName string, key string, value string) {
-	current := c.GetMap(propertyName)
+	current := c.Viper().GetStringMapString(propertyName) // Keeps secrets encrypted
 	if current == nil {
 		current = make(map[string]string)
 	}
 
 	current[key] = value
-	viper.Set(propertyName, current)
-	c.Write("Error adding/updating key [%s] in map property [%s]", key, propertyName)
+	c.Viper().Set(propertyName, current)
+	CheckError(
+		c.writeEdit(yamlEdit{path: append(keyPath(propertyName), key), value: value}),
+		"Error adding/updating key [%s] in map property [%s]", key, propertyName,
+	)
 }
 
 func (c *Configuration) DeleteFromMap(propertyName string, key string) {
-	current := c.GetMap(propertyName)
+	current := c.Viper().GetStringMapString(propertyName) // Keeps secrets encrypted
 	if current == nil {
 		return
 	}
 	if _, ok := current[key]; ok {
 		delete(current, key)
 	}
-	viper.Set(propertyName, current)
-	c.Write("Error deleting key [%s] map property [%s]", key, propertyName)
+	c.Viper().Set(propertyName, current)
+	CheckError(
+		c.writeEdit(yamlEdit{path: append(keyPath(propertyName), key), delete: true}),
+		"Error deleting key [%s] map property [%s]", key, propertyName,
+	)
 }
 
 func (c *Configuration) PrintMapProperty(propertyName string) {
-	current := c.GetMap(propertyName)
+	current := c.Viper().GetStringMapString(propertyName) // Keeps secrets encrypted
 	if current == nil {
 		fmt.Printf("no property [%s] currently defined\n", propertyName)
 	} else {
 		fmt.Printf("---- %s(s) ----\n", propertyName)
 		for k, v := range current {
+			if IsSecretKey(propertyName) || IsSecretKey(k) || IsEncrypted(v) {
+				v = REDACTED
+			}
 			fmt.Printf(" %s --> %s\n", k, v)
 		}
 	}
 }
 
+// Load merges the config files found in each layer, from the system dir up to [cfgFile], with
+// env vars and bound flags on top. See ConfigLayer for the order.
 func (c *Configuration) Load(cfgFile string) {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		c.setConfigPaths()
-	}
-	viper.AutomaticEnv() // read in environment variables that match
-
-	// If a config file is found, read it in.
+	c.file = cfgFile
 	_ = c.readConfig()
 }
 
+// Write saves the settings to Path. The file is replaced atomically while holding a lock shared
+// with other processes, and the previous version is kept as a backup.
 func (c *Configuration) Write(msg string, args ...string) {
 	log.Debugf("writing configuration file")
-	err := viper.WriteConfig()
-	CheckError(err, msg, args)
+	CheckError(c.writeConfig(c.Path()), msg, stringArgs(args)...)
+}
+
+func stringArgs(args []string) []any {
+	converted := make([]any, 0, len(args))
+	for _, arg := range args {
+		converted = append(converted, arg)
+	}
+	return converted
 }
 
 func (c *Configuration) Default() {
-	viper.Reset()
-	c.setUpDefaults()
-	c.setConfigPaths()
+	v := viper.New()
+	c.configureViper(v)
+	c.applyDefaults(v)
+	if version := c.SchemaVersion(); version > 0 {
+		v.Set(CONFIG_VERSION_KEY, version)
+	}
+	c.setViper(v)
 	c.createConfigFile()
 	c.Print()
 }
 
 func (c *Configuration) Print() {
-	Print(viper.AllSettings(), "------ Portfolio Viewer Config Properties [%s] ------", c.LoadedFrom)
+	c.Fprint(os.Stdout)
 }
 
-func (c *Configuration) readConfig() (err error) {
-	if err = viper.ReadInConfig(); err == nil {
-		c.LoadedFrom = viper.ConfigFileUsed()
-		log.Infof("Loaded config from [%s]", c.LoadedFrom)
-		if c.setUpDefaults() {
-			c.Write("updating config with missing defaults")
-		}
-	} else {
-		log.Infof("No config file (%s) found in `.` or `user-home-dir`", c.filename)
+// Fprint writes the properties, with secrets redacted, to w.
+func (c *Configuration) Fprint(w io.Writer) {
+	Fprint(w, redactSecrets(c.Viper().AllSettings()), "------ Portfolio Viewer Config Properties [%s] ------", c.LoadedFrom)
+}
+
+// Path returns the file writes go to: the most specific config file loaded, leaving out the system
+// file and profiles, or, if there is none, where one will be written.
+func (c *Configuration) Path() string {
+	if layer := writableLayer(c.loadedLayers()); layer != nil {
+		return layer.path
+	}
+	return c.configPath()
+}
+
+// readConfig loads every layer and swaps the result in. When no layer can be read the defaults are used.
+func (c *Configuration) readConfig() error {
+	loaded, err := c.load(false)
+	if err == nil && len(loaded.layers) == 0 {
+		err = fmt.Errorf("no config file (%s) found in any config layer", c.filename)
+	}
+	c.swap(loaded.v, loaded.layers, loaded.profile)
+	if err != nil {
+		log.Infof("No config file (%s) found in any config layer", c.filename)
 		if CurrentState().Verbose() {
 			log.Errorf("Error finding/reading config file [%s]. Details: %v", c.filename, err)
 		}
-		c.setUpDefaults()
 		c.LoadedFrom = "defaults"
+		return err
 	}
-	return err
+
+	c.LoadedFrom = baseLayer(loaded.layers).path
+	log.Infof("Loaded config from [%s]", strings.Join(configLayerPaths(loaded.layers), ", "))
+	// Missing defaults are only written to a file the user owns, never the system file.
+	if len(loaded.missing) > 0 && writableLayer(loaded.layers) != nil {
+		c.writeDefaults(loaded.missing)
+	}
+	return nil
+}
+
+// loadedConfig - A freshly loaded set of settings, ready to be swapped in
+type loadedConfig struct {
+	v       *viper.Viper
+	layers  []*configLayer
+	profile string
+	missing []string // The defaults no layer has a value for
+}
+
+// load reads every layer into a new viper with the flags bound and the defaults applied. The
+// current settings are left alone so readers never see a half loaded config. When a layer can't
+// be read the error is returned along with settings holding only the flags and defaults.
+func (c *Configuration) load(strict bool) (*loadedConfig, error) {
+	loaded := &loadedConfig{v: viper.New()}
+	c.configureViper(loaded.v)
+	var err error
+	if loaded.layers, loaded.profile, err = c.readLayers(loaded.v, strict); err != nil {
+		loaded = &loadedConfig{v: viper.New()}
+		c.configureViper(loaded.v)
+	} else if len(loaded.layers) > 0 {
+		c.useFile(loaded.v, baseLayer(loaded.layers).path)
+	}
+	for key, flag := range c.flags {
+		CheckError(loaded.v.BindPFlag(key, flag), "Error binding flag [%s] to config [%s]", flag.Name, key)
+	}
+	loaded.missing = c.applyDefaults(loaded.v)
+	return loaded, err
 }
 
 func (c *Configuration) createConfigFile() {
-	CheckError(viper.WriteConfigAs(c.configPath()), "Unable to write config")
+	CheckError(c.writeConfig(c.configPath()), "Unable to write config")
 	_ = c.readConfig()
 }
 
@@ -204,27 +354,31 @@ func (c *Configuration) configPath() string {
 	}
 }
 
-func (c *Configuration) setUpDefaults() (shouldWrite bool) {
-	if c.defaults != nil {
-		for p, v := range c.defaults {
-			defaulted := c.applyDefault(p, v)
-			shouldWrite = shouldWrite || defaulted
+// applyDefaults applies the defaults to v and returns the properties that had no value.
+func (c *Configuration) applyDefaults(v *viper.Viper) (missing []string) {
+	for property, value := range c.defaults {
+		if v.Get(property) == nil {
+			v.SetDefault(property, value)
+			missing = append(missing, property)
 		}
 	}
+	sort.Strings(missing)
 	return
 }
 
-func (c *Configuration) setConfigPaths() {
-	viper.AddConfigPath(".") //Look in current directory first!
-	viper.AddConfigPath(CurrentState().homeDir)
-	viper.SetConfigType("yaml")
-	viper.SetConfigName(c.filename)
-}
-
-func (c *Configuration) applyDefault(property string, value any) bool {
-	if viper.Get(property) == nil {
-		viper.SetDefault(property, value)
-		return true
-	}
-	return false
+// writeDefaults adds the defaults of the missing properties to the config file, leaving the rest of it as it is.
+func (c *Configuration) writeDefaults(missing []string) {
+	err := c.editFile(
+		c.Path(), func(current map[string]any) []yamlEdit {
+			edits := make([]yamlEdit, 0, len(missing))
+			for _, property := range missing {
+				path := filePath(current, strings.Split(strings.ToLower(property), "."))
+				if _, ok := settingAt(current, path); !ok {
+					edits = append(edits, yamlEdit{path: path, value: c.defaults[property]})
+				}
+			}
+			return edits
+		},
+	)
+	CheckError(err, "updating config with missing defaults")
 }
@@ -0,0 +1,281 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_bind.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+)
+
+// Struct tags understood by Bind and BindSection
+const (
+	CONFIG_TAG   = "config"   // The config key of the field. Defaults to the field name
+	DEFAULT_TAG  = "default"  // The value used when the key is not set
+	REQUIRED_TAG = "required" // "true" when the key must be set
+	MIN_TAG      = "min"      // The minimum value of a number or the minimum length of a string, slice or map
+	MAX_TAG      = "max"      // The maximum value of a number or the maximum length of a string, slice or map
+	ENUM_TAG     = "enum"     // Comma separated list of the allowed values
+	PATTERN_TAG  = "pattern"  // Regular expression that string values must match
+)
+
+// ValidationError - A config value that failed validation and the config key path it was found at
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Key, e.Message)
+}
+
+// ValidationErrors - Every validation failure found while binding a configuration
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(messages, "; "))
+}
+
+// Bind decodes the whole configuration into the struct target points to, applying defaults and
+// validations from the field tags. References are expanded first, see Expand. Validation failures
+// are returned together as ValidationErrors.
+func (c *Configuration) Bind(target any) error {
+	settings, err := c.expandAny("", c.Viper().AllSettings())
+	if err != nil {
+		return err
+	}
+	return bindConfig("", settings.(map[string]any), target)
+}
+
+// BindSection decodes the configuration below key into a new T the same way Bind does.
+func BindSection[T any](c *Configuration, key string) (T, error) {
+	var section T
+	var raw map[string]any
+	if c.Viper().IsSet(key) {
+		var err error
+		if raw, err = cast.ToStringMapE(c.Viper().Get(key)); err != nil {
+			return section, fmt.Errorf("config [%s] is not a section: %w", key, err)
+		}
+		expanded, err := c.expandAny(strings.ToLower(key), raw)
+		if err != nil {
+			return section, err
+		}
+		raw = expanded.(map[string]any)
+	}
+	return section, bindConfig(strings.ToLower(key), raw, &section)
+}
+
+func bindConfig(prefix string, raw map[string]any, target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config can only be bound to a pointer to a struct, not %T", target)
+	}
+	if raw != nil {
+		if err := decodeConfigValue(raw, target); err != nil {
+			return fmt.Errorf("unable to decode config [%s]: %w", prefix, err)
+		}
+	}
+
+	errs := make(ValidationErrors, 0)
+	bindStruct(prefix, raw, value.Elem(), &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func decodeConfigValue(input any, output any) error {
+	decoder, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			TagName:          CONFIG_TAG,
+			WeaklyTypedInput: true,
+			Result:           output,
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.StringToSliceHookFunc(","),
+			),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(input)
+}
+
+func bindStruct(prefix string, raw map[string]any, value reflect.Value, errs *ValidationErrors) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := configFieldName(field)
+		if name == "" {
+			continue
+		}
+		key := joinConfigKey(prefix, name)
+		fieldValue := value.Field(i)
+
+		rawValue, set := raw[name]
+		if !set {
+			if def, ok := field.Tag.Lookup(DEFAULT_TAG); ok {
+				if fieldValue.IsZero() {
+					if err := decodeConfigValue(def, fieldValue.Addr().Interface()); err != nil {
+						*errs = append(*errs, ValidationError{key, fmt.Sprintf("has an invalid default [%s]: %v", def, err)})
+						continue
+					}
+				}
+				set = true
+			} else if field.Tag.Get(REQUIRED_TAG) == "true" {
+				*errs = append(*errs, ValidationError{key, "is required"})
+				continue
+			}
+		}
+		if set {
+			validateConfigField(key, field, fieldValue, errs)
+		}
+		bindNested(key, rawValue, fieldValue, errs)
+	}
+}
+
+func bindNested(key string, raw any, value reflect.Value, errs *ValidationErrors) {
+	switch {
+	case value.Kind() == reflect.Struct:
+		bindStruct(key, cast.ToStringMap(raw), value, errs)
+	case value.Kind() == reflect.Pointer && !value.IsNil() && value.Elem().Kind() == reflect.Struct:
+		bindStruct(key, cast.ToStringMap(raw), value.Elem(), errs)
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Struct:
+		items := cast.ToSlice(raw)
+		for i := 0; i < value.Len(); i++ {
+			var item any
+			if i < len(items) {
+				item = items[i]
+			}
+			bindStruct(fmt.Sprintf("%s[%d]", key, i), cast.ToStringMap(item), value.Index(i), errs)
+		}
+	}
+}
+
+// configFieldName returns the lower cased key of the field, matching viper's keys, or "" when the
+// field is not bound.
+func configFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get(CONFIG_TAG), ",")
+	if name == "-" {
+		return ""
+	}
+	return strings.ToLower(OrDefault(field.Name, name).(string))
+}
+
+func joinConfigKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func validateConfigField(key string, field reflect.StructField, value reflect.Value, errs *ValidationErrors) {
+	fail := func(msg string, args ...any) {
+		*errs = append(*errs, ValidationError{key, fmt.Sprintf(msg, args...)})
+	}
+
+	size, what, measurable := configFieldSize(value)
+	for _, bound := range []string{MIN_TAG, MAX_TAG} {
+		tag, ok := field.Tag.Lookup(bound)
+		if !ok || !measurable {
+			continue
+		}
+		limit, err := strconv.ParseFloat(tag, 64)
+		if err != nil {
+			fail("has an invalid %s tag [%s]", bound, tag)
+		} else if bound == MIN_TAG && size < limit {
+			fail("%s must be at least %s but was %v", what, tag, size)
+		} else if bound == MAX_TAG && size > limit {
+			fail("%s must be at most %s but was %v", what, tag, size)
+		}
+	}
+
+	values := configFieldValues(value)
+	if tag, ok := field.Tag.Lookup(ENUM_TAG); ok {
+		allowed := strings.Split(tag, ",")
+		for _, v := range values {
+			if !Contains(allowed, v) {
+				fail("must be one of [%s] but was [%s]", strings.Join(allowed, ", "), v)
+			}
+		}
+	}
+	if tag, ok := field.Tag.Lookup(PATTERN_TAG); ok {
+		regex := NewRegex(tag)
+		if !regex.IsValid() {
+			fail("has an invalid pattern [%s]", tag)
+			return
+		}
+		for _, v := range values {
+			if !regex.Matches(v) {
+				fail("must match [%s] but was [%s]", tag, v)
+			}
+		}
+	}
+}
+
+// configFieldSize returns the number used by min and max: the value of numbers and the length of
+// strings, slices and maps.
+func configFieldSize(value reflect.Value) (float64, string, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), "value", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), "value", true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), "value", true
+	case reflect.String, reflect.Slice, reflect.Map:
+		return float64(value.Len()), "length", true
+	}
+	return 0, "", false
+}
+
+// configFieldValues returns the values checked by enum and pattern: the value itself or, for
+// slices, each of its items.
+func configFieldValues(value reflect.Value) []string {
+	if value.Kind() == reflect.Slice {
+		values := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			values = append(values, fmt.Sprint(value.Index(i).Interface()))
+		}
+		return values
+	}
+	return []string{fmt.Sprint(value.Interface())}
+}
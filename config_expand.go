@@ -0,0 +1,245 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_expand.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ENV_REFERENCE_PREFIX   = "env:"   // ${env:HOME} expands to the env var
+	STATE_REFERENCE_PREFIX = "state:" // ${state:dataDir} expands to a directory or property of the State
+	REFERENCE_DEFAULT_SEP  = ":-"     // ${key:-fallback} expands to fallback when key is unset or empty
+)
+
+// ExpansionError - A ${reference} in the config value of Key that can't be resolved
+type ExpansionError struct {
+	Key       string
+	Reference string
+	Message   string
+}
+
+func (e *ExpansionError) Error() string {
+	return fmt.Sprintf("unable to expand ${%s} in config [%s]: %s", e.Reference, e.Key, e.Message)
+}
+
+// Expand resolves the references in value the same way values read from the configuration are:
+//
+//	${other.key}      the value of another config property
+//	${env:NAME}       an environment variable
+//	${state:dataDir}  homeDir, workDir, dataDir, tempDir, appName, version or user of the State
+//	${ref:-fallback}  fallback, itself expanded, when ref is unset or empty
+//	~/path            a path in the user's home dir
+//
+// $${ is left as a literal ${.
+func (c *Configuration) Expand(value string) (string, error) {
+	return c.expand("", value, nil)
+}
+
+// Resolve returns the property as a string with its references expanded, see Expand.
+func (c *Configuration) Resolve(propertyName string) (string, error) {
+	return c.resolve(strings.ToLower(propertyName), nil)
+}
+
+// expandedValue expands the strings in value, logging references that can't be resolved and
+// leaving them as they are.
+func (c *Configuration) expandedValue(propertyName string, value any) any {
+	expanded, err := c.expandAny(strings.ToLower(propertyName), value)
+	LogError(err, "Unable to expand config property [%s]", propertyName)
+	return expanded
+}
+
+// expandAny decrypts and expands every string in value, including those nested in maps and lists.
+// Strings that can't be expanded are left as they are and their errors joined.
+func (c *Configuration) expandAny(key string, value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return c.expandString(key, v)
+	case map[string]any:
+		expanded := make(map[string]any, len(v))
+		var errs []error
+		for k, item := range v {
+			itemKey := k
+			if key != "" {
+				itemKey = key + "." + k
+			}
+			var err error
+			expanded[k], err = c.expandAny(itemKey, item)
+			errs = append(errs, err)
+		}
+		return expanded, errors.Join(errs...)
+	case []any:
+		expanded := make([]any, len(v))
+		var errs []error
+		for i, item := range v {
+			var err error
+			expanded[i], err = c.expandAny(key, item)
+			errs = append(errs, err)
+		}
+		return expanded, errors.Join(errs...)
+	case []string:
+		expanded := make([]string, len(v))
+		var errs []error
+		for i, item := range v {
+			var err error
+			expanded[i], err = c.expandString(key, item)
+			errs = append(errs, err)
+		}
+		return expanded, errors.Join(errs...)
+	}
+	return value, nil
+}
+
+// expandString decrypts or expands value, returning it unchanged when it can't be expanded.
+func (c *Configuration) expandString(key string, value string) (string, error) {
+	if IsEncrypted(value) {
+		return c.decryptValue(key, value), nil
+	}
+	expanded, err := c.expand(key, value, []string{key})
+	if err != nil {
+		return value, err
+	}
+	return expanded, nil
+}
+
+// resolve expands the value of key. resolving holds the keys being expanded, outermost first, to
+// detect reference cycles.
+func (c *Configuration) resolve(key string, resolving []string) (string, error) {
+	for i, outer := range resolving {
+		if outer == key {
+			cycle := strings.Join(append(resolving[i:], key), " -> ")
+			return "", &ExpansionError{Key: resolving[0], Reference: key, Message: "reference cycle " + cycle}
+		}
+	}
+	value := c.Viper().GetString(key)
+	if IsEncrypted(value) {
+		return c.decryptValue(key, value), nil
+	}
+	return c.expand(key, value, append(resolving, key))
+}
+
+func (c *Configuration) expand(key string, value string, resolving []string) (string, error) {
+	if value == "~" || strings.HasPrefix(value, "~/") || strings.HasPrefix(value, "~"+string(filepath.Separator)) {
+		value = CurrentState().HomeDir() + value[1:]
+	}
+
+	var expanded strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			expanded.WriteString(value)
+			return expanded.String(), nil
+		}
+		if start > 0 && value[start-1] == '$' {
+			expanded.WriteString(value[:start-1] + "${")
+			value = value[start+2:]
+			continue
+		}
+		end := referenceEnd(value, start+2)
+		if end < 0 {
+			return "", &ExpansionError{Key: key, Reference: value[start+2:], Message: "missing closing }"}
+		}
+		resolved, err := c.expandReference(key, value[start+2:end], resolving)
+		if err != nil {
+			return "", err
+		}
+		expanded.WriteString(value[:start] + resolved)
+		value = value[end+1:]
+	}
+}
+
+// referenceEnd returns the index of the } closing the reference that starts at from, allowing for
+// references nested in its default.
+func referenceEnd(value string, from int) int {
+	depth := 0
+	for i := from; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "${"):
+			depth++
+			i++
+		case value[i] == '}' && depth == 0:
+			return i
+		case value[i] == '}':
+			depth--
+		}
+	}
+	return -1
+}
+
+func (c *Configuration) expandReference(key string, reference string, resolving []string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(reference, REFERENCE_DEFAULT_SEP)
+	var value string
+	var err error
+	switch {
+	case strings.HasPrefix(name, ENV_REFERENCE_PREFIX):
+		value = os.Getenv(strings.TrimPrefix(name, ENV_REFERENCE_PREFIX))
+	case strings.HasPrefix(name, STATE_REFERENCE_PREFIX):
+		if value, err = stateReference(strings.TrimPrefix(name, STATE_REFERENCE_PREFIX)); err != nil {
+			return "", &ExpansionError{Key: key, Reference: reference, Message: err.Error()}
+		}
+	case c.Viper().IsSet(name):
+		if value, err = c.resolve(strings.ToLower(name), resolving); err != nil {
+			return "", err
+		}
+	}
+
+	if value != "" {
+		return value, nil
+	}
+	if hasFallback {
+		return c.expand(key, fallback, resolving)
+	}
+	return "", &ExpansionError{Key: key, Reference: reference, Message: "it is unset or empty and has no :-default"}
+}
+
+func stateReference(name string) (string, error) {
+	state := CurrentState()
+	switch strings.ToLower(name) {
+	case "homedir":
+		return state.HomeDir(), nil
+	case "workdir":
+		return state.WorkDir(), nil
+	case "datadir":
+		return state.DataDir(), nil
+	case "tempdir":
+		return state.TempDir(), nil
+	case "appname":
+		return state.AppName(), nil
+	case "version":
+		return state.Version(), nil
+	case "user":
+		return state.User(), nil
+	}
+	return "", fmt.Errorf("the State has no %s, expected homeDir, workDir, dataDir, tempDir, appName, version or user", name)
+}
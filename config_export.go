@@ -0,0 +1,199 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_export.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/apex/log"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportedSetting - One key of the effective configuration and the layer that supplied it
+type ExportedSetting struct {
+	Key    string      `json:"key" yaml:"key"`
+	Value  any         `json:"value" yaml:"value"`
+	Layer  ConfigLayer `json:"layer" yaml:"layer"`
+	Origin string      `json:"origin,omitempty" yaml:"origin,omitempty"`
+}
+
+// ExportOptions - How WriteExport writes the configuration
+type ExportOptions struct {
+	Format  string // yaml (the default) or json
+	Sources bool   // Write each key with the layer that supplied it instead of the nested settings
+	Redact  bool   // Mask secret keys and encrypted values
+}
+
+// Export returns the effective settings with references expanded. Encrypted values are masked when
+// redact is set and left encrypted otherwise, secrets are never exported in the clear.
+func (c *Configuration) Export(redact bool) map[string]any {
+	return c.exportSettings("", c.Viper().AllSettings(), redact)
+}
+
+// ExportSources returns every key of Export, sorted, along with the layer that supplied its value.
+func (c *Configuration) ExportSources(redact bool) []ExportedSetting {
+	flat := flattenSettings("", c.Export(redact), nil)
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	settings := make([]ExportedSetting, 0, len(keys))
+	for _, key := range keys {
+		setting := ExportedSetting{Key: key, Value: flat[key], Layer: LAYER_DEFAULTS}
+		if source := c.Explain(key).Source; source != nil {
+			setting.Layer, setting.Origin = source.Layer, source.Origin
+		}
+		settings = append(settings, setting)
+	}
+	return settings
+}
+
+// WriteExport writes Export, or ExportSources when options.Sources is set, to w.
+func (c *Configuration) WriteExport(w io.Writer, options ExportOptions) error {
+	var exported any = c.Export(options.Redact)
+	if options.Sources {
+		exported = c.ExportSources(options.Redact)
+	}
+	switch options.Format {
+	case "", "yaml", "yml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(exported); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(exported)
+	}
+	return fmt.Errorf("unsupported config export format [%s], expected yaml or json", options.Format)
+}
+
+// Import writes the settings in the file at path to Path and reloads. With replace the file's
+// settings take the place of the current ones, otherwise they are merged over them. Redacted values,
+// as written by Export, keep their current value and the current schema version is kept unless the
+// file has one.
+func (c *Configuration) Import(path string, replace bool) error {
+	imported, err := readSettings(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config [%s] to import: %w", path, err)
+	}
+	target := c.Path()
+	err = c.editFile(
+		target, func(current map[string]any) []yamlEdit {
+			keepRedacted(imported, current)
+			if !replace {
+				return updateEdits(current, imported)
+			}
+			if version, ok := current[CONFIG_VERSION_KEY]; ok {
+				if _, set := imported[CONFIG_VERSION_KEY]; !set {
+					imported[CONFIG_VERSION_KEY] = version
+				}
+			}
+			return replaceEdits(current, imported)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to import config [%s] into [%s]: %w", path, target, err)
+	}
+	log.Infof("Imported config [%s] into [%s]", path, target)
+	return c.readConfig()
+}
+
+// DiffDefaults returns the settings that differ from the defaults passed to NewConfiguration,
+// with secrets redacted.
+func (c *Configuration) DiffDefaults() []ConfigDifference {
+	defaults := viper.New()
+	for property, value := range c.defaults {
+		defaults.Set(property, value)
+	}
+	return diffSettings(redactSecrets(defaults.AllSettings()), c.Export(true))
+}
+
+// DiffFile returns the settings that differ between the effective configuration and the config
+// file at path, with secrets redacted.
+func (c *Configuration) DiffFile(path string) ([]ConfigDifference, error) {
+	settings, err := readSettings(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config [%s] to compare: %w", path, err)
+	}
+	return diffSettings(c.Export(true), redactSecrets(settings)), nil
+}
+
+func (c *Configuration) exportSettings(prefix string, settings map[string]any, redact bool) map[string]any {
+	exported := make(map[string]any, len(settings))
+	for name, value := range settings {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]any); ok {
+			exported[name] = c.exportSettings(key, nested, redact)
+			continue
+		}
+		switch {
+		case redact && (IsSecretKey(name) || IsEncrypted(value)):
+			exported[name] = REDACTED
+		case IsEncrypted(value):
+			exported[name] = value
+		default:
+			exported[name] = c.expandedValue(key, value)
+		}
+	}
+	return exported
+}
+
+// keepRedacted replaces the redacted values in imported with their value in current, dropping them
+// if there is none.
+func keepRedacted(imported map[string]any, current map[string]any) {
+	for name, value := range imported {
+		switch v := value.(type) {
+		case map[string]any:
+			nested, _ := current[name].(map[string]any)
+			keepRedacted(v, nested)
+		case string:
+			if v != REDACTED {
+				continue
+			}
+			if previous, ok := current[name]; ok {
+				imported[name] = previous
+			} else {
+				delete(imported, name)
+			}
+		}
+	}
+}
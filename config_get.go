@@ -0,0 +1,264 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_get.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// ErrConfigNotSet is returned by GetAs when the property has no value in any layer or default.
+var ErrConfigNotSet = errors.New("config property is not set")
+
+// CONFIG_TIME_FORMATS are the layouts, tried in order, that time properties are parsed with.
+var CONFIG_TIME_FORMATS = []string{time.RFC3339Nano, time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", time.DateOnly}
+
+// ByteSize - A size in bytes, written in config files like 512, 10MB or 1.5GiB
+type ByteSize uint64
+
+const (
+	BYTE ByteSize = 1
+	KB            = 1000 * BYTE
+	MB            = 1000 * KB
+	GB            = 1000 * MB
+	TB            = 1000 * GB
+	KIB           = 1024 * BYTE
+	MIB           = 1024 * KIB
+	GIB           = 1024 * MIB
+	TIB           = 1024 * GIB
+)
+
+var byteSizeUnits = map[string]ByteSize{
+	"": BYTE, "b": BYTE, "kb": KB, "mb": MB, "gb": GB, "tb": TB, "kib": KIB, "mib": MIB, "gib": GIB, "tib": TIB,
+}
+
+// ParseByteSize parses a number with an optional unit, B, KB, MB, GB and TB being powers of 1000 and
+// KiB, MiB, GiB and TiB powers of 1024. Units are case-insensitive.
+func ParseByteSize(s string) (ByteSize, error) {
+	trimmed := strings.TrimSpace(s)
+	split := strings.IndexFunc(trimmed, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	number, unit := trimmed, ""
+	if split >= 0 {
+		number, unit = trimmed[:split], strings.TrimSpace(trimmed[split:])
+	}
+	multiplier, ok := byteSizeUnits[strings.ToLower(unit)]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit [%s] in byte size [%s], expected B, KB, MB, GB, TB, KiB, MiB, GiB or TiB", unit, s)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || number == "" {
+		return 0, fmt.Errorf("byte size [%s] does not start with a number", s)
+	}
+	bytes := value * float64(multiplier)
+	if bytes > math.MaxUint64 {
+		return 0, fmt.Errorf("byte size [%s] is too large", s)
+	}
+	return ByteSize(bytes), nil
+}
+
+func (b ByteSize) String() string {
+	for _, unit := range []struct {
+		size ByteSize
+		name string
+	}{{TIB, "TiB"}, {GIB, "GiB"}, {MIB, "MiB"}, {KIB, "KiB"}} {
+		if b >= unit.size && b%unit.size == 0 {
+			return fmt.Sprintf("%d%s", b/unit.size, unit.name)
+		}
+	}
+	return fmt.Sprintf("%dB", uint64(b))
+}
+
+// ConfigValueError - A config value that can't be converted to the type it was read as
+type ConfigValueError struct {
+	Key   string
+	Value any
+	Type  string
+	Err   error
+}
+
+func (e *ConfigValueError) Error() string {
+	return fmt.Sprintf("config [%s] value [%v] is not a valid %s: %v", e.Key, e.Value, e.Type, e.Err)
+}
+
+func (e *ConfigValueError) Unwrap() error {
+	return e.Err
+}
+
+// GetAs returns the property converted to T. Besides the basic types, string lists and maps, T can be
+// a time.Duration ("5m"), time.Time (see CONFIG_TIME_FORMATS), ByteSize ("10MB"), *url.URL or a
+// struct decoded like BindSection. Unset properties return ErrConfigNotSet, values that can't be
+// converted a ConfigValueError.
+func GetAs[T any](c *Configuration, key string) (T, error) {
+	var result T
+	if !c.IsSet(key) {
+		return result, fmt.Errorf("%w: %s", ErrConfigNotSet, key)
+	}
+	value := c.GetValue(key)
+
+	var converted any
+	var err error
+	switch any(result).(type) {
+	case string:
+		converted, err = cast.ToStringE(value)
+	case bool:
+		converted, err = cast.ToBoolE(value)
+	case int:
+		converted, err = cast.ToIntE(value)
+	case int64:
+		converted, err = cast.ToInt64E(value)
+	case uint:
+		converted, err = cast.ToUintE(value)
+	case uint64:
+		converted, err = cast.ToUint64E(value)
+	case float64:
+		converted, err = cast.ToFloat64E(value)
+	case []string:
+		converted, err = cast.ToStringSliceE(value)
+	case map[string]string:
+		converted, err = cast.ToStringMapStringE(value)
+	case map[string]any:
+		converted, err = cast.ToStringMapE(value)
+	case time.Duration:
+		converted, err = toDuration(value)
+	case time.Time:
+		converted, err = toTime(value)
+	case ByteSize:
+		converted, err = toByteSize(value)
+	case *url.URL:
+		converted, err = toURL(value)
+	default:
+		if kind := reflect.TypeOf(result); kind != nil && kind.Kind() == reflect.Struct {
+			return BindSection[T](c, key)
+		}
+		if err = decodeConfigValue(value, &result); err == nil {
+			return result, nil
+		}
+	}
+	if err != nil {
+		return result, &ConfigValueError{Key: key, Value: value, Type: fmt.Sprintf("%T", result), Err: err}
+	}
+	return converted.(T), nil
+}
+
+// GetOr returns the property converted to T, or defaultValue when it is unset. Unlike
+// GetIntWithDefault a zero value that is set is returned as is. Values that can't be converted are
+// logged and defaultValue is returned.
+func GetOr[T any](c *Configuration, key string, defaultValue T) T {
+	value, err := GetAs[T](c, key)
+	if errors.Is(err, ErrConfigNotSet) {
+		return defaultValue
+	}
+	if err != nil {
+		LogError(err, "Using the default for config [%s]", key)
+		return defaultValue
+	}
+	return value
+}
+
+// GetDuration returns the property parsed as a duration like 90s or 1h30m.
+func (c *Configuration) GetDuration(propertyName string) (time.Duration, error) {
+	return GetAs[time.Duration](c, propertyName)
+}
+
+// GetTime returns the property parsed with the first of CONFIG_TIME_FORMATS that matches.
+func (c *Configuration) GetTime(propertyName string) (time.Time, error) {
+	return GetAs[time.Time](c, propertyName)
+}
+
+// GetByteSize returns the property parsed as a size like 512, 10MB or 1.5GiB, see ParseByteSize.
+func (c *Configuration) GetByteSize(propertyName string) (ByteSize, error) {
+	return GetAs[ByteSize](c, propertyName)
+}
+
+// GetURL returns the property parsed as an absolute URL.
+func (c *Configuration) GetURL(propertyName string) (*url.URL, error) {
+	return GetAs[*url.URL](c, propertyName)
+}
+
+func toDuration(value any) (time.Duration, error) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return time.ParseDuration(strings.TrimSpace(v))
+	}
+	if n, err := cast.ToInt64E(value); err == nil && n == 0 {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("expected a duration with a unit, like 300ms, 5m or 1h30m")
+}
+
+func toTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range CONFIG_TIME_FORMATS {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("expected a time in one of the formats %v", CONFIG_TIME_FORMATS)
+}
+
+func toByteSize(value any) (ByteSize, error) {
+	if s, ok := value.(string); ok {
+		return ParseByteSize(s)
+	}
+	n, err := cast.ToInt64E(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a positive number of bytes or a size like 10MB")
+	}
+	return ByteSize(n), nil
+}
+
+func toURL(value any) (*url.URL, error) {
+	s, err := cast.ToStringE(value)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.IsAbs() || (parsed.Host == "" && parsed.Opaque == "") {
+		return nil, fmt.Errorf("expected an absolute URL like https://example.com")
+	}
+	return parsed, nil
+}
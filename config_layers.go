@@ -0,0 +1,438 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_layers.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigLayer - A source of configuration values. Layers are merged in the order below, each
+// overriding the ones before it:
+//
+//	defaults, system, xdg, home, project, file, env, flags
+//
+// The active profile of each config file sits directly above the file it comes from.
+type ConfigLayer string
+
+const (
+	LAYER_DEFAULTS ConfigLayer = "defaults" // Defaults passed to NewConfiguration
+	LAYER_SYSTEM   ConfigLayer = "system"   // /etc/<name>/<name> (%ProgramData% on windows)
+	LAYER_XDG      ConfigLayer = "xdg"      // <user config dir>/<name>/<name>, e.g. $XDG_CONFIG_HOME
+	LAYER_HOME     ConfigLayer = "home"     // The config file in the user's home dir
+	LAYER_PROJECT  ConfigLayer = "project"  // Config files from the repo root down to the working dir
+	LAYER_FILE     ConfigLayer = "file"     // The file passed to Load
+	LAYER_ENV      ConfigLayer = "env"      // <PREFIX>_<KEY> environment variables
+	LAYER_FLAGS    ConfigLayer = "flags"    // Flags bound with BindFlag or BindFlags
+	LAYER_PROFILE  ConfigLayer = "profile"  // The active profile of a config file, see SetProfile
+)
+
+// systemConfigRoot is where the system layer looks for the <name> directory
+var systemConfigRoot = defaultSystemConfigRoot()
+
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// envPrefixRegex matches the characters of an app name that can't be used in an env var prefix
+var envPrefixRegex = regexp.MustCompile(`[^A-Z0-9_]+`)
+
+func defaultSystemConfigRoot() string {
+	if runtime.GOOS == "windows" {
+		return os.Getenv("ProgramData")
+	}
+	return "/etc"
+}
+
+type configLayer struct {
+	layer    ConfigLayer
+	path     string
+	section  string // The section of the file holding the settings, e.g. profiles.prod
+	settings map[string]any
+}
+
+func (l *configLayer) origin() string {
+	if l.section == "" {
+		return l.path
+	}
+	return fmt.Sprintf("%s (%s)", l.path, l.section)
+}
+
+// baseLayer returns the highest layer that isn't a profile, the file the configuration is loaded from.
+func baseLayer(layers []*configLayer) *configLayer {
+	for i := len(layers) - 1; i > 0; i-- {
+		if layers[i].layer != LAYER_PROFILE {
+			return layers[i]
+		}
+	}
+	return layers[0]
+}
+
+// writableLayer returns the highest layer that isn't a profile or the system file, the file writes
+// go to, or nil if there is none.
+func writableLayer(layers []*configLayer) *configLayer {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if layers[i].layer != LAYER_PROFILE && layers[i].layer != LAYER_SYSTEM {
+			return layers[i]
+		}
+	}
+	return nil
+}
+
+// ConfigValueSource - Where a configuration value came from. Origin is the file, env var or flag.
+type ConfigValueSource struct {
+	Layer  ConfigLayer
+	Origin string
+	Value  any
+}
+
+// ConfigExplanation - The effective value of a key, the source that supplied it and any lower
+// layers it overrides, highest first.
+type ConfigExplanation struct {
+	Key        string
+	Value      any
+	Source     *ConfigValueSource
+	Overridden []ConfigValueSource
+}
+
+func (e *ConfigExplanation) String() string {
+	if e.Source == nil {
+		return fmt.Sprintf("%s is not set", e.Key)
+	}
+	lines := []string{fmt.Sprintf("%s = %v (%s)", e.Key, e.Value, describeSource(*e.Source))}
+	for _, source := range e.Overridden {
+		lines = append(lines, fmt.Sprintf("  overrides %v (%s)", source.Value, describeSource(source)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func describeSource(source ConfigValueSource) string {
+	if source.Origin == "" {
+		return string(source.Layer)
+	}
+	return fmt.Sprintf("%s: %s", source.Layer, source.Origin)
+}
+
+// SetEnvPrefix makes env vars named <PREFIX>_<KEY> override the config files. Dots and dashes in
+// keys become underscores, e.g. SUZY_SERVER_PORT for server.port.
+func (c *Configuration) SetEnvPrefix(prefix string) *Configuration {
+	c.envPrefix = prefix
+	c.Viper().SetEnvPrefix(prefix)
+	return c
+}
+
+// BindFlag makes the flag, when set on the command line, override every other layer for key.
+func (c *Configuration) BindFlag(key string, flag *pflag.Flag) *Configuration {
+	if flag == nil {
+		return c
+	}
+	if c.flags == nil {
+		c.flags = make(map[string]*pflag.Flag)
+	}
+	c.flags[strings.ToLower(key)] = flag
+	CheckError(c.Viper().BindPFlag(key, flag), "Error binding flag [%s] to config [%s]", flag.Name, key)
+	return c
+}
+
+// BindFlags binds every flag annotated with a config key, e.g. via CmdConfig.SetFlagConfigKey.
+func (c *Configuration) BindFlags(flags *pflag.FlagSet) *Configuration {
+	flags.VisitAll(
+		func(flag *pflag.Flag) {
+			if key := flagAnnotation(flag, CONFIG_KEY_ANNOTATION); key != "" {
+				c.BindFlag(key, flag)
+			}
+		},
+	)
+	return c
+}
+
+// Explain reports the effective value of key and which layer supplied it.
+func (c *Configuration) Explain(key string) *ConfigExplanation {
+	key = strings.ToLower(key)
+	sources := c.sources(key)
+	explanation := &ConfigExplanation{Key: key, Value: c.GetValue(key)}
+	if len(sources) > 0 {
+		explanation.Source = &sources[0]
+		explanation.Overridden = sources[1:]
+	}
+	return explanation
+}
+
+// sources returns every source holding a value for the lower cased key, highest first.
+func (c *Configuration) sources(key string) []ConfigValueSource {
+	sources := make([]ConfigValueSource, 0)
+	if flag, ok := c.flags[key]; ok && flag.Changed {
+		sources = append(sources, ConfigValueSource{LAYER_FLAGS, "--" + flag.Name, flag.Value.String()})
+	}
+	if name := c.envVar(key); name != "" {
+		if value, ok := os.LookupEnv(name); ok {
+			sources = append(sources, ConfigValueSource{LAYER_ENV, name, value})
+		}
+	}
+	layers := c.loadedLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		if value, ok := lookupSetting(layers[i].settings, key); ok {
+			sources = append(sources, ConfigValueSource{layers[i].layer, layers[i].origin(), value})
+		}
+	}
+	for property, value := range c.defaults {
+		if strings.ToLower(property) == key {
+			sources = append(sources, ConfigValueSource{Layer: LAYER_DEFAULTS, Value: value})
+		}
+	}
+	return sources
+}
+
+func (c *Configuration) envVar(key string) string {
+	if c.envPrefix == "" {
+		return strings.ToUpper(envKeyReplacer.Replace(key))
+	}
+	return strings.ToUpper(envKeyReplacer.Replace(c.envPrefix + "_" + key))
+}
+
+func (c *Configuration) configureViper(v *viper.Viper) {
+	v.SetConfigType("yaml")
+	v.SetEnvPrefix(c.envPrefix)
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+}
+
+func (c *Configuration) loadedLayers() []*configLayer {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.layers
+}
+
+// swap replaces the settings, the layers they were read from and the profile applied to them.
+func (c *Configuration) swap(v *viper.Viper, layers []*configLayer, profile string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.v = v
+	c.layers = layers
+	c.activeProfile = profile
+}
+
+// useFile points writes at filename and returns it.
+func (c *Configuration) useFile(v *viper.Viper, filename string) string {
+	v.SetConfigFile(filename)
+	if ext := strings.TrimPrefix(filepath.Ext(filename), "."); Contains(viper.SupportedExts, ext) {
+		v.SetConfigType(ext)
+	}
+	return filename
+}
+
+// readLayers merges the config files of every layer, and the profile selected by them, into v,
+// lowest layer first. Unreadable files are skipped unless strict is set or the file was passed to
+// Load.
+func (c *Configuration) readLayers(v *viper.Viper, strict bool) ([]*configLayer, string, error) {
+	layers := make([]*configLayer, 0)
+	for _, layer := range c.layerFiles() {
+		settings, err := readSettings(layer.path)
+		if err != nil {
+			if strict || layer.layer == LAYER_FILE {
+				return nil, "", err
+			}
+			LogError(err, "Skipping unreadable %s config [%s]", layer.layer, layer.path)
+			continue
+		}
+		layer.settings = settings
+		layers = append(layers, layer)
+	}
+	// Only the file writes go to is rewritten by migrations, the others are migrated as they are read
+	writable := writableLayer(layers)
+	for _, layer := range layers {
+		layer.settings = c.migrate(layer.path, layer.settings, layer == writable)
+	}
+
+	profile := c.selectProfile(layers)
+	if profile != "" {
+		var err error
+		if layers, err = c.overlayProfile(layers, profile, strict); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, layer := range layers {
+		if err := v.MergeConfigMap(layer.settings); err != nil {
+			return nil, "", err
+		}
+	}
+	return layers, profile, nil
+}
+
+// layerFiles finds the config file of each layer, lowest first. A file found by more than one
+// layer belongs to the highest of them.
+func (c *Configuration) layerFiles() []*configLayer {
+	name := strings.TrimPrefix(c.filename, ".")
+	candidates := make([]*configLayer, 0)
+	add := func(layer ConfigLayer, path string) {
+		if path == "" {
+			return
+		}
+		for i, candidate := range candidates {
+			if candidate.path == path {
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				break
+			}
+		}
+		candidates = append(candidates, &configLayer{layer: layer, path: path})
+	}
+
+	if systemConfigRoot != "" {
+		add(LAYER_SYSTEM, findConfigFile(filepath.Join(systemConfigRoot, name), name))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		add(LAYER_XDG, findConfigFile(filepath.Join(dir, name), name))
+	}
+	state := CurrentState()
+	if state.homeDir != "" {
+		add(LAYER_HOME, findConfigFile(state.homeDir, c.filename))
+	}
+	for _, dir := range projectDirs(state.workDir) {
+		add(LAYER_PROJECT, findConfigFile(dir, c.filename))
+	}
+	if c.file != "" {
+		if abs, err := filepath.Abs(c.file); err == nil {
+			add(LAYER_FILE, abs)
+		}
+	}
+	return candidates
+}
+
+// findConfigFile returns the path of name, with any extension viper supports, in dir or "" if there is none.
+func findConfigFile(dir string, name string) string {
+	for _, ext := range viper.SupportedExts {
+		if path := filepath.Join(dir, name+"."+ext); isFile(path) {
+			return path
+		}
+	}
+	if path := filepath.Join(dir, name); isFile(path) {
+		return path
+	}
+	return ""
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// projectDirs returns the dirs from the repo root containing workDir down to workDir. Outside of a
+// repo only workDir itself is returned.
+func projectDirs(workDir string) []string {
+	if workDir == "" {
+		return nil
+	}
+	dirs := make([]string, 0)
+	for dir := filepath.Clean(workDir); ; dir = filepath.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+		if PathExists(filepath.Join(dir, ".git")) {
+			return dirs
+		}
+		if filepath.Dir(dir) == dir {
+			return []string{filepath.Clean(workDir)}
+		}
+	}
+}
+
+// readSettings parses the config file at path. Keys are lower cased like viper's, but unlike
+// viper's AllSettings map keys containing dots are kept as they are.
+func readSettings(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := parseSettings(content, configFileType(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse config [%s]: %w", path, err)
+	}
+	return settings, nil
+}
+
+func parseSettings(content []byte, fileType string) (map[string]any, error) {
+	settings := make(map[string]any)
+	var err error
+	switch fileType {
+	case "yaml", "yml":
+		err = yaml.Unmarshal(content, &settings)
+	case "json":
+		err = json.Unmarshal(content, &settings)
+	case "toml":
+		err = toml.Unmarshal(content, &settings)
+	default:
+		v := viper.New()
+		v.SetConfigType(fileType)
+		err = v.ReadConfig(bytes.NewReader(content))
+		settings = v.AllSettings()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lowerCaseKeys(settings), nil
+}
+
+func lowerCaseKeys(settings map[string]any) map[string]any {
+	lowered := make(map[string]any, len(settings))
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]any:
+			value = lowerCaseKeys(v)
+		case map[any]any:
+			value = lowerCaseKeys(cast.ToStringMap(v))
+		}
+		lowered[strings.ToLower(key)] = value
+	}
+	return lowered
+}
+
+// lookupSetting finds a dotted key in nested settings, whether the settings split map keys
+// containing dots, like viper's AllSettings, or keep them whole, like a parsed file.
+func lookupSetting(settings map[string]any, key string) (any, bool) {
+	return settingAt(settings, filePath(settings, keyPath(key)))
+}
+
+func configLayerPaths(layers []*configLayer) []string {
+	paths := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		if !Contains(paths, layer.path) {
+			paths = append(paths, layer.path)
+		}
+	}
+	return paths
+}
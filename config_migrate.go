@@ -0,0 +1,249 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_migrate.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cast"
+)
+
+// CONFIG_VERSION_KEY holds the schema version of a config file. Files without it are version 0.
+const CONFIG_VERSION_KEY = "config_version"
+
+// MigrationStep changes the settings of a config file in place and describes what it did. Steps
+// that have nothing to do return an empty description.
+type MigrationStep func(settings map[string]any) (string, error)
+
+// ConfigMigration - The steps that upgrade a config file to Version
+type ConfigMigration struct {
+	Version     int
+	Description string
+	Steps       []MigrationStep
+}
+
+func NewConfigMigration(version int, description string, steps ...MigrationStep) *ConfigMigration {
+	return &ConfigMigration{
+		Version:     version,
+		Description: description,
+		Steps:       steps,
+	}
+}
+
+// AddMigrations registers migrations that are run on Load against every config file with an older
+// schema version. Only the file writes go to, see Path, is rewritten, after being backed up like
+// any other write. Other files, such as the system one, are migrated each time they are read.
+func (c *Configuration) AddMigrations(migrations ...*ConfigMigration) *Configuration {
+	c.migrations = append(c.migrations, migrations...)
+	sort.SliceStable(c.migrations, func(i, j int) bool { return c.migrations[i].Version < c.migrations[j].Version })
+	return c
+}
+
+// SchemaVersion returns the version config files are migrated to.
+func (c *Configuration) SchemaVersion() int {
+	if len(c.migrations) == 0 {
+		return 0
+	}
+	return c.migrations[len(c.migrations)-1].Version
+}
+
+// RenameKey moves the value of from to to.
+func RenameKey(from string, to string) MigrationStep {
+	return func(settings map[string]any) (string, error) {
+		if moved, err := moveSetting(settings, from, to); !moved {
+			return "", err
+		}
+		return fmt.Sprintf("renamed [%s] to [%s]", from, to), nil
+	}
+}
+
+// MoveIntoMap moves key into the map property mapProperty, keeping the last part of its name.
+func MoveIntoMap(key string, mapProperty string) MigrationStep {
+	name := key[strings.LastIndex(key, ".")+1:]
+	to := mapProperty + "." + name
+	return func(settings map[string]any) (string, error) {
+		if moved, err := moveSetting(settings, key, to); !moved {
+			return "", err
+		}
+		return fmt.Sprintf("moved [%s] into map [%s]", key, mapProperty), nil
+	}
+}
+
+// TransformValue replaces the value of key with the result of transform.
+func TransformValue(key string, transform func(value any) (any, error)) MigrationStep {
+	return func(settings map[string]any) (string, error) {
+		value, ok := lookupSetting(settings, strings.ToLower(key))
+		if !ok {
+			return "", nil
+		}
+		transformed, err := transform(value)
+		if err != nil {
+			return "", fmt.Errorf("unable to transform [%s]: %w", key, err)
+		}
+		setSetting(settings, key, transformed)
+		return fmt.Sprintf("transformed [%s]", key), nil
+	}
+}
+
+// DropKey removes key.
+func DropKey(key string) MigrationStep {
+	return func(settings map[string]any) (string, error) {
+		if !deleteSetting(settings, key) {
+			return "", nil
+		}
+		return fmt.Sprintf("dropped [%s]", key), nil
+	}
+}
+
+// migrate upgrades the settings read from path to the latest schema version and, with write,
+// rewrites the file. When a migration fails the file is left alone and its settings are used as they are.
+func (c *Configuration) migrate(path string, settings map[string]any, write bool) map[string]any {
+	from := cast.ToInt(settings[CONFIG_VERSION_KEY])
+	latest := c.SchemaVersion()
+	if from >= latest {
+		return settings
+	}
+
+	migrated := copySettings(settings)
+	summary := make([]string, 0)
+	for _, migration := range c.migrations {
+		if migration.Version <= from {
+			continue
+		}
+		for _, step := range migration.Steps {
+			line, err := step(migrated)
+			if err != nil {
+				LogError(err, "Unable to migrate config [%s] to version %d (%s)", path, migration.Version, migration.Description)
+				return settings
+			}
+			if line != "" {
+				summary = append(summary, fmt.Sprintf("v%d: %s", migration.Version, line))
+			}
+		}
+	}
+	migrated[CONFIG_VERSION_KEY] = latest
+	if !write {
+		log.Debugf("Migrated config [%s] from version %d to %d without rewriting it", path, from, latest)
+		return migrated
+	}
+
+	err := c.editFile(
+		path, func(current map[string]any) []yamlEdit {
+			return replaceEdits(current, migrated)
+		},
+	)
+	if err != nil {
+		LogError(err, "Unable to write migrated config [%s]", path)
+		return migrated
+	}
+	log.Infof(
+		"Migrated config [%s] from version %d to %d%s", path, from, latest,
+		strings.Join(append([]string{""}, summary...), "\n  - "),
+	)
+	return migrated
+}
+
+func copyFile(from string, to string) error {
+	content, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(to, content, info.Mode().Perm())
+}
+
+func copySettings(settings map[string]any) map[string]any {
+	copied := make(map[string]any, len(settings))
+	for key, value := range settings {
+		if m, ok := value.(map[string]any); ok {
+			value = copySettings(m)
+		}
+		copied[key] = value
+	}
+	return copied
+}
+
+func hasSetting(settings map[string]any, key string) bool {
+	_, ok := lookupSetting(settings, strings.ToLower(key))
+	return ok
+}
+
+func moveSetting(settings map[string]any, from string, to string) (bool, error) {
+	value, ok := lookupSetting(settings, strings.ToLower(from))
+	if !ok {
+		return false, nil
+	}
+	if hasSetting(settings, to) {
+		return false, fmt.Errorf("cannot move [%s] to [%s] as it is already set", from, to)
+	}
+	deleteSetting(settings, from)
+	setSetting(settings, to, value)
+	return true, nil
+}
+
+// setSetting sets a dotted key in nested settings, creating maps along the way.
+func setSetting(settings map[string]any, key string, value any) {
+	parts := strings.Split(strings.ToLower(key), ".")
+	current := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+}
+
+// deleteSetting removes a dotted key from nested settings and reports whether it was there.
+func deleteSetting(settings map[string]any, key string) bool {
+	parts := strings.Split(strings.ToLower(key), ".")
+	current := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			return false
+		}
+		current = next
+	}
+	if _, ok := current[parts[len(parts)-1]]; !ok {
+		return false
+	}
+	delete(current, parts[len(parts)-1])
+	return true
+}
@@ -0,0 +1,279 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_profiles.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+const (
+	PROFILE_KEY       = "profile"  // Selects the profile, in a config file or as <PREFIX>_PROFILE
+	PROFILES_KEY      = "profiles" // Holds the profiles defined inside a config file
+	PROFILE_FLAG_NAME = "profile"
+)
+
+// ConfigDifference - A key whose value differs between two sets of settings. A nil From or To
+// means the key isn't set on that side.
+type ConfigDifference struct {
+	Key  string
+	From any
+	To   any
+}
+
+func (d ConfigDifference) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Key, describeValue(d.Key, d.From), describeValue(d.Key, d.To))
+}
+
+func describeValue(key string, value any) string {
+	if value == nil {
+		return "(unset)"
+	}
+	if IsSecretKey(key) || IsEncrypted(value) {
+		return REDACTED
+	}
+	return fmt.Sprint(value)
+}
+
+// SetProfile selects the named profile over the PROFILE_KEY env var and setting. The profile's
+// settings, from the profiles section of each config file and from sibling files named
+// <name>.<profile>.<ext>, are merged over the file they belong to. An empty name selects the base
+// settings alone. A configuration that is already loaded is reloaded.
+func (c *Configuration) SetProfile(name string) *Configuration {
+	c.lock.Lock()
+	c.profile = name
+	c.profileSet = true
+	c.lock.Unlock()
+	if c.LoadedFrom != "" {
+		_ = c.readConfig()
+	}
+	return c
+}
+
+// Profile returns the name of the profile merged over the base settings, "" if there is none.
+func (c *Configuration) Profile() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.activeProfile
+}
+
+// Profiles returns the sorted names of the profiles defined by the loaded config files.
+func (c *Configuration) Profiles() []string {
+	names := make([]string, 0)
+	add := func(name string) {
+		if !Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, layer := range c.loadedLayers() {
+		if layer.layer == LAYER_PROFILE {
+			continue
+		}
+		if profiles, ok := layer.settings[PROFILES_KEY].(map[string]any); ok {
+			for name := range profiles {
+				add(name)
+			}
+		}
+		for name := range siblingProfiles(layer.path) {
+			add(name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SwitchProfile makes the named profile the default by writing it to the config file, then
+// reloads. A profile set with SetProfile or the env var still takes precedence.
+func (c *Configuration) SwitchProfile(name string) error {
+	name = strings.ToLower(name)
+	if name != "" && !Contains(c.Profiles(), name) {
+		return fmt.Errorf("there is no config profile [%s], expected one of %v", name, c.Profiles())
+	}
+	c.Update(PROFILE_KEY, name)
+	return c.readConfig()
+}
+
+// ProfileSettings returns the settings as they would be with the named profile applied, without
+// changing the active profile. An empty name returns the base settings.
+func (c *Configuration) ProfileSettings(name string) (map[string]any, error) {
+	name = strings.ToLower(name)
+	if name != "" && !Contains(c.Profiles(), name) {
+		return nil, fmt.Errorf("there is no config profile [%s], expected one of %v", name, c.Profiles())
+	}
+	preview := c.clone()
+	preview.profile, preview.profileSet = name, true
+	loaded, err := preview.load(false)
+	if err != nil {
+		return nil, err
+	}
+	return loaded.v.AllSettings(), nil
+}
+
+// DiffProfiles returns the settings that differ between two profiles, "" being the base settings.
+func (c *Configuration) DiffProfiles(from string, to string) ([]ConfigDifference, error) {
+	fromSettings, err := c.ProfileSettings(from)
+	if err != nil {
+		return nil, err
+	}
+	toSettings, err := c.ProfileSettings(to)
+	if err != nil {
+		return nil, err
+	}
+	return diffSettings(fromSettings, toSettings), nil
+}
+
+// selectProfile picks the profile set with SetProfile, else the one named by the env var, else the
+// one named by the highest layer that sets PROFILE_KEY.
+func (c *Configuration) selectProfile(layers []*configLayer) string {
+	c.lock.RLock()
+	profile, profileSet := c.profile, c.profileSet
+	c.lock.RUnlock()
+	if profileSet {
+		return strings.ToLower(profile)
+	}
+	if value, ok := os.LookupEnv(c.envVar(PROFILE_KEY)); ok {
+		return strings.ToLower(value)
+	}
+	for i := len(layers) - 1; i >= 0; i-- {
+		if value, ok := layers[i].settings[PROFILE_KEY]; ok {
+			return strings.ToLower(cast.ToString(value))
+		}
+	}
+	return ""
+}
+
+// overlayProfile places the profile section of each file, then its sibling profile file, directly
+// above the file's layer.
+func (c *Configuration) overlayProfile(layers []*configLayer, profile string, strict bool) ([]*configLayer, error) {
+	overlaid := make([]*configLayer, 0, len(layers))
+	found := false
+	for _, layer := range layers {
+		overlaid = append(overlaid, layer)
+		section := PROFILES_KEY + "." + profile
+		if settings, ok := lookupSetting(layer.settings, section); ok {
+			if settings, ok := settings.(map[string]any); ok {
+				overlaid = append(
+					overlaid, &configLayer{layer: LAYER_PROFILE, path: layer.path, section: section, settings: settings},
+				)
+				found = true
+			}
+		}
+		if path, ok := siblingProfiles(layer.path)[profile]; ok {
+			settings, err := readSettings(path)
+			if err != nil {
+				if strict {
+					return nil, err
+				}
+				LogError(err, "Skipping unreadable profile config [%s]", path)
+				continue
+			}
+			overlaid = append(overlaid, &configLayer{layer: LAYER_PROFILE, path: path, settings: c.migrate(path, settings, false)})
+			found = true
+		}
+	}
+	if !found {
+		log.Warnf("Config profile [%s] was not found in any config layer", profile)
+	}
+	return overlaid, nil
+}
+
+// siblingProfiles finds the <name>.<profile>.<ext> files next to the config file at path, keyed
+// by lower case profile name.
+func siblingProfiles(path string) map[string]string {
+	profiles := make(map[string]string)
+	dir := filepath.Dir(path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return profiles
+	}
+	base := filepath.Base(path)
+	if ext := strings.TrimPrefix(filepath.Ext(base), "."); Contains(viper.SupportedExts, ext) {
+		base = strings.TrimSuffix(base, "."+ext)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := strings.TrimPrefix(filepath.Ext(name), ".")
+		if entry.IsDir() || !Contains(viper.SupportedExts, ext) || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		profile := strings.TrimSuffix(strings.TrimPrefix(name, base+"."), "."+ext)
+		if profile == "" || strings.Contains(profile, ".") || Contains(viper.SupportedExts, profile) {
+			continue
+		}
+		profiles[strings.ToLower(profile)] = filepath.Join(dir, name)
+	}
+	return profiles
+}
+
+// diffSettings compares two sets of nested settings key by key, sorted by key.
+func diffSettings(from map[string]any, to map[string]any) []ConfigDifference {
+	flatFrom, flatTo := flattenSettings("", from, nil), flattenSettings("", to, nil)
+	keys := make([]string, 0, len(flatFrom))
+	for key := range flatFrom {
+		keys = append(keys, key)
+	}
+	for key := range flatTo {
+		if _, ok := flatFrom[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	diffs := make([]ConfigDifference, 0)
+	for _, key := range keys {
+		if !reflect.DeepEqual(flatFrom[key], flatTo[key]) {
+			diffs = append(diffs, ConfigDifference{Key: key, From: flatFrom[key], To: flatTo[key]})
+		}
+	}
+	return diffs
+}
+
+// flattenSettings maps the dotted key of every leaf in settings to its value.
+func flattenSettings(prefix string, settings map[string]any, flat map[string]any) map[string]any {
+	if flat == nil {
+		flat = make(map[string]any)
+	}
+	for key, value := range settings {
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenSettings(prefix+key+".", nested, flat)
+		} else {
+			flat[prefix+key] = value
+		}
+	}
+	return flat
+}
@@ -0,0 +1,245 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_secrets.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// SECRET_PREFIX marks a config value as encrypted
+const SECRET_PREFIX = "enc:"
+
+// ErrNoSecretKey is returned when a secret can't be decrypted because there is no key
+var ErrNoSecretKey = errors.New("no secret key found")
+
+// SetSecretKeySource sets the env var and key file the encryption key for secret values is read
+// from. The env var wins when both are present. By default they are <PREFIX>_SECRET_KEY (or
+// CONFIG_SECRET_KEY without an env prefix) and <home dir>/<config filename>.key.
+//
+// The key is hashed with SHA-256, not stretched with a password KDF, so it must be a full entropy
+// random key such as the base64 of 32 random bytes (openssl rand -base64 32), the same as the
+// generated key file holds, never a memorable passphrase.
+func (c *Configuration) SetSecretKeySource(envVar string, keyFile string) *Configuration {
+	c.secretKeyEnv = envVar
+	c.secretKeyFile = keyFile
+	return c
+}
+
+// UpdateSecret encrypts value and stores it under propertyName. A key file is created if no key exists yet.
+func (c *Configuration) UpdateSecret(propertyName string, value string) error {
+	encrypted, err := c.encryptSecret(value)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt config property [%s]: %w", propertyName, err)
+	}
+	c.Viper().Set(propertyName, encrypted)
+	if err = c.writeEdit(yamlEdit{path: keyPath(propertyName), value: encrypted}); err != nil {
+		return fmt.Errorf("unable to save secret config property [%s]: %w", propertyName, err)
+	}
+	return nil
+}
+
+// IsEncrypted reports whether value is an encrypted secret.
+func IsEncrypted(value any) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, SECRET_PREFIX)
+}
+
+// decryptValue returns value with any encrypted secret decrypted. Secrets that can't be decrypted
+// are logged and returned empty so the cipher text is never mistaken for the secret.
+func (c *Configuration) decryptValue(propertyName string, value string) string {
+	if !IsEncrypted(value) {
+		return value
+	}
+	plain, err := c.decryptSecret(value)
+	if err != nil {
+		LogError(err, "Unable to decrypt config property [%s]", propertyName)
+		return ""
+	}
+	return plain
+}
+
+func (c *Configuration) encryptSecret(value string) (string, error) {
+	gcm, err := c.secretCipher(true)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return SECRET_PREFIX + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *Configuration) decryptSecret(value string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, SECRET_PREFIX))
+	if err != nil {
+		return "", fmt.Errorf("malformed secret: %w", err)
+	}
+	gcm, err := c.secretCipher(false)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("malformed secret: too short")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("secret could not be decrypted with the current key: %w", err)
+	}
+	return string(plain), nil
+}
+
+// secretCipher builds an AES-256-GCM cipher from the SHA-256 of the key. SHA-256 only fits the key
+// to AES-256, it adds no work factor, which is why keys have to be full entropy.
+func (c *Configuration) secretCipher(create bool) (cipher.AEAD, error) {
+	key, err := c.secretKey(create)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (c *Configuration) secretKey(create bool) ([]byte, error) {
+	envVar, keyFile := c.secretKeySource()
+	if key := strings.TrimSpace(os.Getenv(envVar)); key != "" {
+		return []byte(key), nil
+	}
+
+	key, err := readSecretKeyFile(keyFile)
+	if os.IsNotExist(err) && create {
+		return createSecretKeyFile(keyFile)
+	}
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: set %s or create [%s]", ErrNoSecretKey, envVar, keyFile)
+	}
+	return key, err
+}
+
+func readSecretKeyFile(keyFile string) ([]byte, error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.TrimSpace(string(content))
+	if key == "" {
+		return nil, fmt.Errorf("%w: [%s] is empty", ErrNoSecretKey, keyFile)
+	}
+	return []byte(key), nil
+}
+
+func (c *Configuration) secretKeySource() (envVar string, keyFile string) {
+	envVar, keyFile = c.secretKeyEnv, c.secretKeyFile
+	if envVar == "" {
+		envVar = c.envVar("secret_key")
+		if c.envPrefix == "" {
+			envVar = "CONFIG_SECRET_KEY"
+		}
+	}
+	if keyFile == "" {
+		keyFile = filepath.Join(CurrentState().homeDir, c.filename+".key")
+	}
+	return
+}
+
+// createSecretKeyFile writes a new random key to keyFile. When another process creates the file
+// first its key is used instead, so every process encrypts with the same key.
+func createSecretKeyFile(keyFile string) ([]byte, error) {
+	raw := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, raw); err != nil {
+		return nil, err
+	}
+	key := []byte(base64.StdEncoding.EncodeToString(raw))
+
+	f, err := os.OpenFile(keyFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		return waitForSecretKeyFile(keyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create secret key file [%s]: %w", keyFile, err)
+	}
+	defer f.Close()
+	if _, err = f.Write(append(key, '\n')); err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to write secret key file [%s]: %w", keyFile, err)
+	}
+	log.Infof("Created secret key file [%s]", keyFile)
+	return key, nil
+}
+
+// waitForSecretKeyFile reads the key file another process has just created, giving it a moment
+// to write the key.
+func waitForSecretKeyFile(keyFile string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		key, err := readSecretKeyFile(keyFile)
+		if !errors.Is(err, ErrNoSecretKey) || attempt == 50 {
+			return key, err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// redactSecrets returns a copy of settings with secret keys and encrypted values masked.
+func redactSecrets(settings map[string]any) map[string]any {
+	redacted := make(map[string]any, len(settings))
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]any:
+			redacted[key] = redactSecrets(v)
+		default:
+			if IsSecretKey(key) || IsEncrypted(value) {
+				redacted[key] = REDACTED
+			} else {
+				redacted[key] = value
+			}
+		}
+	}
+	return redacted
+}
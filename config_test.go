@@ -0,0 +1,793 @@
+package golang_utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigurationsAreIndependent(t *testing.T) {
+	dir := t.TempDir()
+	appFile := filepath.Join(dir, "app.yaml")
+	pluginFile := filepath.Join(dir, "plugin.yaml")
+	assert.NoError(t, os.WriteFile(appFile, []byte("name: app\n"), 0644))
+	assert.NoError(t, os.WriteFile(pluginFile, []byte("name: plugin\ncolor: red\n"), 0644))
+
+	app := NewConfiguration("app.yaml", false, Properties{"size": 3})
+	app.Load(appFile)
+	plugin := NewConfiguration("plugin.yaml", false, nil)
+	plugin.Load(pluginFile)
+
+	assert.Equal(t, "app", app.Get("name"))
+	assert.Equal(t, "plugin", plugin.Get("name"))
+	assert.False(t, app.IsSet("color"))
+	assert.Equal(t, 3, app.GetInt("size"))
+	assert.False(t, plugin.IsSet("size"), "defaults should only apply to their own configuration")
+
+	app.Update("name", "renamed")
+	assert.Equal(t, "plugin", plugin.Get("name"))
+	assert.Equal(t, appFile, app.Path())
+}
+
+type serverConfig struct {
+	Host    string        `config:"host" required:"true" pattern:"^[a-z.]+$"`
+	Port    int           `config:"port" default:"8080" min:"1" max:"65535"`
+	Timeout time.Duration `config:"timeout" default:"5s"`
+}
+
+type appConfig struct {
+	Name    string         `config:"name" required:"true"`
+	Mode    string         `config:"mode" default:"dev" enum:"dev,prod"`
+	Tags    []string       `config:"tags" max:"2"`
+	Server  serverConfig   `config:"server"`
+	Mirrors []serverConfig `config:"mirrors"`
+}
+
+func newBindTestConfig(t *testing.T, content string) *Configuration {
+	file := filepath.Join(t.TempDir(), "bind.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	config := NewConfiguration("bind.yaml", false, nil)
+	config.Load(file)
+	return config
+}
+
+func TestBindAppliesDefaults(t *testing.T) {
+	config := newBindTestConfig(t, "name: suzy\nserver:\n  host: localhost\n")
+
+	var cfg appConfig
+	assert.NoError(t, config.Bind(&cfg))
+	assert.Equal(t, "suzy", cfg.Name)
+	assert.Equal(t, "dev", cfg.Mode)
+	assert.Equal(t, serverConfig{Host: "localhost", Port: 8080, Timeout: 5 * time.Second}, cfg.Server)
+
+	server, err := BindSection[serverConfig](config, "server")
+	assert.NoError(t, err)
+	assert.Equal(t, cfg.Server, server)
+}
+
+func TestBindReportsEveryValidationError(t *testing.T) {
+	config := newBindTestConfig(
+		t, `
+mode: test
+tags: [a, b, c]
+server:
+  port: 0
+mirrors:
+  - host: ok.example
+  - host: NOT OK
+`,
+	)
+
+	var cfg appConfig
+	err := config.Bind(&cfg)
+	errs, ok := err.(ValidationErrors)
+	assert.True(t, ok, "validation failures should be returned as ValidationErrors")
+
+	keys := make([]string, 0, len(errs))
+	for _, e := range errs {
+		keys = append(keys, e.Key)
+	}
+	assert.Equal(t, []string{"name", "mode", "tags", "server.host", "server.port", "mirrors[1].host"}, keys)
+	assert.ErrorContains(t, err, "[mode] must be one of [dev, prod] but was [test]")
+	assert.ErrorContains(t, err, "[server.port] value must be at least 1 but was 0")
+
+	_, err = BindSection[serverConfig](config, "server")
+	assert.Equal(t, ValidationErrors{{"server.host", "is required"}, {"server.port", "value must be at least 1 but was 0"}}, err)
+}
+
+func TestWatchReloadsChangesAndKeepsLastGoodConfig(t *testing.T) {
+	Reset()
+	defer Reset()
+	events := make(chan *ConfigChangedEvent, 10)
+	EventBus.Register(
+		"config.", NewEmptyConfigChangedEvent(), func(event Event) error {
+			events <- event.(*ConfigChangedEvent)
+			return nil
+		},
+	)
+	nextEvent := func() *ConfigChangedEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("no config event was sent")
+			return nil
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "watch.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("name: suzy\nsize: 1\n"), 0644))
+	config := NewConfiguration("watch.yaml", false, nil)
+	config.Load(file)
+
+	watcher, err := config.Watch(
+		10*time.Millisecond, func(candidate *Configuration) error {
+			if candidate.GetInt("size") > 10 {
+				return fmt.Errorf("size is too big")
+			}
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	defer watcher.Stop()
+
+	assert.NoError(t, os.WriteFile(file, []byte("name: suzy\nsize: 2\ncolor: red\n"), 0644))
+	event := nextEvent()
+	assert.Equal(t, CONFIG_CHANGED_EVENT, event.Name())
+	assert.Equal(t, []string{"color", "size"}, event.Changed)
+	assert.Equal(t, 2, config.GetInt("size"))
+
+	assert.NoError(t, os.WriteFile(file, []byte("name: suzy\nsize: 20\n"), 0644))
+	event = nextEvent()
+	assert.Equal(t, CONFIG_RELOAD_FAILED_EVENT, event.Name())
+	assert.Equal(t, 2, config.GetInt("size"), "invalid changes should be rolled back")
+
+	assert.NoError(t, os.WriteFile(file, []byte("name: [suzy\n"), 0644))
+	event = nextEvent()
+	assert.Equal(t, CONFIG_RELOAD_FAILED_EVENT, event.Name())
+	assert.Equal(t, "red", config.Get("color"))
+}
+
+func TestWatchValidatorsCanReadSecrets(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "watch.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("name: suzy\n"), 0644))
+	config := NewConfiguration("watch.yaml", false, nil).SetSecretKeySource("SUZY_WATCH_KEY", filepath.Join(dir, "watch.key"))
+	config.Load(file)
+	assert.NoError(t, config.UpdateSecret("api.token", "s3cr3t"))
+
+	var validated string
+	watcher, err := config.Watch(
+		time.Hour, func(candidate *Configuration) error {
+			validated = candidate.Get("api.token")
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	defer watcher.Stop()
+
+	watcher.Reload()
+	assert.Equal(t, "s3cr3t", validated, "the candidate should use the configuration's secret key")
+}
+
+func TestReloadsNeverExposeAHalfLoadedConfig(t *testing.T) {
+	config := newBindTestConfig(t, "name: suzy\n")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			assert.NoError(t, config.readConfig())
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+			assert.Equal(t, "suzy", config.Get("name"))
+		}
+	}
+}
+
+func TestLayersMergeInOrderAndExplainTheirSource(t *testing.T) {
+	root := t.TempDir()
+	write := func(path string, content string) string {
+		path = filepath.Join(root, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	write("etc/suzy/suzy.yaml", "level: system\ncolor: grey\nsize: 1\n")
+	write("xdg/suzy/suzy.yaml", "color: white\n")
+	write("home/.suzy", "color: green\n")
+	write("repo/.git/HEAD", "")
+	repoFile := write("repo/.suzy", "color: blue\nname: repo\n")
+	workFile := write("repo/sub/.suzy.yaml", "name: sub\n")
+
+	defer func(root string) { systemConfigRoot = root }(systemConfigRoot)
+	systemConfigRoot = filepath.Join(root, "etc")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "xdg"))
+	t.Setenv("SUZY_SIZE", "5")
+	state := withFreshState(t)
+	state.homeDir = filepath.Join(root, "home")
+	state.workDir = filepath.Join(root, "repo", "sub")
+
+	flags := pflag.NewFlagSet("suzy", pflag.ContinueOnError)
+	flags.String("level", "", "the level")
+	_ = flags.SetAnnotation("level", CONFIG_KEY_ANNOTATION, []string{"level"})
+
+	config := NewConfiguration(".suzy", true, Properties{"mode": "dev"}).SetEnvPrefix("SUZY").BindFlags(flags)
+	config.Load("")
+	assert.NoError(t, flags.Parse([]string{"--level", "flag"}))
+
+	assert.Equal(t, workFile, config.Path(), "writes should go to the most specific file")
+	assert.Equal(t, "sub", config.Get("name"))
+	assert.Equal(t, "blue", config.Get("color"))
+	assert.Equal(t, 5, config.GetInt("size"))
+	assert.Equal(t, "flag", config.Get("level"))
+	assert.Equal(t, "dev", config.Get("mode"))
+
+	explained := config.Explain("color")
+	assert.Equal(t, ConfigValueSource{LAYER_PROJECT, repoFile, "blue"}, *explained.Source)
+	layers := make([]ConfigLayer, 0)
+	for _, source := range explained.Overridden {
+		layers = append(layers, source.Layer)
+	}
+	assert.Equal(t, []ConfigLayer{LAYER_HOME, LAYER_XDG, LAYER_SYSTEM}, layers)
+
+	assert.Equal(t, LAYER_ENV, config.Explain("size").Source.Layer)
+	assert.Equal(t, "SUZY_SIZE", config.Explain("size").Source.Origin)
+	assert.Equal(t, LAYER_FLAGS, config.Explain("level").Source.Layer)
+	assert.Equal(t, LAYER_DEFAULTS, config.Explain("mode").Source.Layer)
+	assert.Equal(t, "missing is not set", config.Explain("missing").String())
+}
+
+func TestWritesOnlyTouchTheUsersOwnKeys(t *testing.T) {
+	root := t.TempDir()
+	systemFile := filepath.Join(root, "etc", "suzy", "suzy.yaml")
+	homeFile := filepath.Join(root, "home", ".suzy.yaml")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(systemFile), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(homeFile), 0755))
+	assert.NoError(t, os.WriteFile(systemFile, []byte("level: system\ncolor: grey\n"), 0644))
+	assert.NoError(t, os.WriteFile(homeFile, []byte("color: green\n"), 0644))
+
+	defer func(root string) { systemConfigRoot = root }(systemConfigRoot)
+	systemConfigRoot = filepath.Join(root, "etc")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "xdg"))
+	t.Setenv("SUZY_SIZE", "5")
+	state := withFreshState(t)
+	state.homeDir = filepath.Join(root, "home")
+	state.workDir = filepath.Join(root, "work")
+
+	config := NewConfiguration(".suzy", true, Properties{"mode": "dev"}).
+		SetEnvPrefix("SUZY").
+		AddMigrations(NewConfigMigration(1, "rename level", RenameKey("level", "tier")))
+	config.Load("")
+	assert.Equal(t, homeFile, config.Path(), "writes should never go to the system file")
+	assert.Equal(t, "system", config.Get("tier"), "the system file should be migrated as it is read")
+	config.Update("name", "q")
+	config.Write("writing config")
+
+	content, err := os.ReadFile(homeFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "color: green\nconfig_version: 1\nmode: dev\nname: q\n", string(content))
+	content, err = os.ReadFile(systemFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "level: system\ncolor: grey\n", string(content))
+}
+
+func TestWritesKeepProfileValuesInTheirProfile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "suzy.yaml")
+	original := "host: localhost\nprofiles:\n  prod:\n    host: example.com\n    port: 443\n"
+	assert.NoError(t, os.WriteFile(file, []byte(original), 0644))
+
+	config := NewConfiguration("suzy.yaml", false, nil).SetProfile("prod")
+	config.Load(file)
+	assert.Equal(t, "example.com", config.Get("host"))
+	config.Update("name", "q")
+	config.Write("writing config")
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, original+"name: q\n", string(content))
+}
+
+func TestSecretsAreEncryptedAtRest(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secrets.yaml")
+	keyFile := filepath.Join(dir, "secrets.key")
+	assert.NoError(t, os.WriteFile(file, []byte("name: suzy\n"), 0644))
+
+	config := NewConfiguration("secrets.yaml", false, nil).SetSecretKeySource("SUZY_TEST_SECRET_KEY", keyFile)
+	config.Load(file)
+	_, err := config.decryptSecret(SECRET_PREFIX + "AAAA")
+	assert.ErrorIs(t, err, ErrNoSecretKey)
+
+	assert.NoError(t, config.UpdateSecret("api.token", "s3cr3t"))
+	config.UpdateMap("api", "user", "q")
+	assert.FileExists(t, keyFile, "a key file should be created for the first secret")
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "s3cr3t")
+	assert.Contains(t, string(content), SECRET_PREFIX)
+
+	reloaded := NewConfiguration("secrets.yaml", false, nil).SetSecretKeySource("SUZY_TEST_SECRET_KEY", keyFile)
+	reloaded.Load(file)
+	assert.Equal(t, "s3cr3t", reloaded.Get("api.token"))
+	assert.Equal(t, map[string]string{"token": "s3cr3t", "user": "q"}, reloaded.GetMap("api"))
+	assert.Equal(
+		t,
+		map[string]any{"name": "suzy", "api": map[string]any{"token": REDACTED, "user": "q"}},
+		redactSecrets(reloaded.Viper().AllSettings()),
+	)
+
+	t.Setenv("SUZY_TEST_SECRET_KEY", "a different key")
+	assert.Equal(t, "", reloaded.Get("api.token"), "secrets should not decrypt with the wrong key")
+}
+
+func TestSecretsDecryptNextToUnresolvedReferences(t *testing.T) {
+	t.Setenv("SUZY_TEST_SECRET_KEY", "expand-test-key")
+	config := NewConfiguration("secrets.yaml", false, nil).SetSecretKeySource("SUZY_TEST_SECRET_KEY", "")
+	secret, err := config.encryptSecret("s3cr3t")
+	assert.NoError(t, err)
+	config.Viper().Set("api", map[string]any{"token": secret, "url": "${nope}"})
+	config.Viper().Set("tokens", []string{secret, "${nope}"})
+
+	assert.Equal(t, map[string]string{"token": "s3cr3t", "url": "${nope}"}, config.GetMap("api"))
+	assert.Equal(t, []string{"s3cr3t", "${nope}"}, config.GetList("tokens"))
+}
+
+func TestUpdateSecretReportsFailedWrites(t *testing.T) {
+	t.Setenv("CONFIG_SECRET_KEY", "secret-write-test-key")
+	config := newBindTestConfig(t, "name: suzy\n")
+	dir := filepath.Dir(config.Path())
+	assert.NoError(t, os.RemoveAll(dir))
+	assert.NoError(t, os.WriteFile(dir, nil, 0644))
+
+	assert.Error(t, config.UpdateSecret("api.token", "s3cr3t"), "a secret that wasn't saved should be reported")
+}
+
+func TestConcurrentKeyFileCreationAgreesOnOneKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "race.key")
+	keys := make([]string, 5)
+	var wg sync.WaitGroup
+	for i := range keys {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key, err := createSecretKeyFile(keyFile)
+			assert.NoError(t, err)
+			keys[i] = string(key)
+		}(i)
+	}
+	wg.Wait()
+
+	content, err := os.ReadFile(keyFile)
+	assert.NoError(t, err)
+	for _, key := range keys {
+		assert.Equal(t, string(bytes.TrimSpace(content)), key)
+	}
+	info, err := os.Stat(keyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestMigrationsUpgradeOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "migrate.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("user: suzy\ntoken: abc\ncolor: red\nlegacy: true\nsize: \"3\"\n"), 0644))
+
+	newConfig := func() *Configuration {
+		return NewConfiguration("migrate.yaml", false, nil).AddMigrations(
+			NewConfigMigration(2, "move credentials", MoveIntoMap("token", "auth"), RenameKey("user", "auth.user")),
+			NewConfigMigration(
+				1, "tidy up", DropKey("legacy"), TransformValue(
+					"size", func(value any) (any, error) {
+						return cast.ToIntE(value)
+					},
+				),
+			),
+		)
+	}
+	config := newConfig()
+	config.Load(file)
+
+	assert.Equal(t, 2, config.SchemaVersion())
+	assert.Equal(t, 2, config.GetInt(CONFIG_VERSION_KEY))
+	assert.Equal(t, map[string]string{"token": "abc", "user": "suzy"}, config.GetMap("auth"))
+	assert.False(t, config.IsSet("legacy"))
+	assert.Equal(t, 3, config.GetValue("size"))
+
+	assert.Len(t, config.Backups(), 1)
+	backup, err := os.ReadFile(config.Backups()[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(backup), "legacy: true")
+
+	reloaded := NewConfiguration("migrate.yaml", false, nil)
+	reloaded.Load(file)
+	assert.Equal(t, "abc", reloaded.Get("auth.token"), "the migrated file should have been written")
+
+	newConfig().Load(file)
+	assert.Len(t, config.Backups(), 1, "files at the latest version should not be migrated again")
+}
+
+func TestFailedMigrationsLeaveTheFileAlone(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "migrate.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("user: suzy\nname: q\n"), 0644))
+
+	config := NewConfiguration("migrate.yaml", false, nil).
+		AddMigrations(NewConfigMigration(1, "rename user", RenameKey("user", "name")))
+	config.Load(file)
+
+	assert.Equal(t, "suzy", config.Get("user"))
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "user: suzy\nname: q\n", string(content))
+}
+
+func TestWritesKeepRotatingBackupsThatCanBeRestored(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "backup.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("size: 0\n"), 0600))
+	config := NewConfiguration("backup.yaml", false, nil).SetBackupCount(2)
+	config.Load(file)
+
+	for _, size := range []string{"1", "2", "3"} {
+		config.Update("size", size)
+	}
+	assert.Len(t, config.Backups(), 2, "only the newest backups should be kept")
+	assert.Equal(t, 3, config.GetInt("size"))
+
+	info, err := os.Stat(file)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the file's permissions should survive a rewrite")
+
+	assert.NoError(t, config.Restore(2))
+	assert.Equal(t, 1, config.GetInt("size"))
+	assert.Error(t, config.Restore(3))
+}
+
+func TestConcurrentWritesNeverCorruptTheFile(t *testing.T) {
+	files := map[string]string{"concurrent.yaml": "name: suzy\n", "concurrent.json": `{"name": "suzy"}`}
+	for name, content := range files {
+		file := filepath.Join(t.TempDir(), name)
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				config := NewConfiguration(name, false, nil)
+				config.Load(file)
+				for j := 0; j < 5; j++ {
+					config.Update(fmt.Sprintf("writer%d", i), fmt.Sprint(j))
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		settings, err := readSettings(file)
+		assert.NoError(t, err)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, "4", fmt.Sprint(settings[fmt.Sprintf("writer%d", i)]), "%s should keep every writer's update", name)
+		}
+		entries, err := os.ReadDir(filepath.Dir(file))
+		assert.NoError(t, err)
+		for _, entry := range entries {
+			assert.NotContains(t, entry.Name(), ".tmp.", "temp files should not be left behind")
+		}
+	}
+}
+
+func TestYamlUpdatesPreserveCommentsAndOrder(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "commented.yaml")
+	original := `# The app's config
+name: suzy # who we are
+servers:
+  - alpha # primary
+colors:
+  # favourite first
+  fav: "red"
+  other: blue
+size: 3
+`
+	assert.NoError(t, os.WriteFile(file, []byte(original), 0644))
+	config := NewConfiguration("commented.yaml", false, nil).SetBackupCount(0)
+	config.Load(file)
+
+	config.Update("name", "q")
+	config.UpdateList("servers", "beta")
+	config.UpdateMap("colors", "fav", "green")
+	config.UpdateMap("colors", "new", "pink")
+	config.DeleteFromMap("colors", "other")
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(
+		t, `# The app's config
+name: q # who we are
+servers:
+  - alpha # primary
+  - beta
+colors:
+  # favourite first
+  fav: "green"
+  new: pink
+size: 3
+`, string(content),
+	)
+}
+
+func TestWholeFileWritesPreserveComments(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "commented.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("# The app's config\nname: suzy # who we are\nuser: q\n"), 0644))
+	config := NewConfiguration("commented.yaml", false, Properties{"size": 3}).
+		SetBackupCount(0).
+		AddMigrations(NewConfigMigration(1, "rename user", RenameKey("user", "owner")))
+	config.Load(file)
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(
+		t, "# The app's config\nname: suzy # who we are\nconfig_version: 1\nowner: q\nsize: 3\n", string(content),
+		"migrations and missing defaults should only touch their own keys",
+	)
+
+	other := filepath.Join(dir, "other.yaml")
+	assert.NoError(t, os.WriteFile(other, []byte("name: other\ncolor: red\n"), 0644))
+	assert.NoError(t, config.Import(other, false))
+	content, err = os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(
+		t, "# The app's config\nname: other # who we are\nconfig_version: 1\nowner: q\nsize: 3\ncolor: red\n",
+		string(content),
+	)
+}
+
+func TestMapKeysWithDotsStayOneKey(t *testing.T) {
+	config := newBindTestConfig(t, "hosts:\n  db.example.com: 10.0.0.1\n")
+	config.UpdateMap("hosts", "api.example.com", "1.2.3.4")
+
+	content, err := os.ReadFile(config.Path())
+	assert.NoError(t, err)
+	assert.Equal(t, "hosts:\n  db.example.com: 10.0.0.1\n  api.example.com: 1.2.3.4\n", string(content))
+	config.Load(config.Path())
+	assert.Equal(t, map[string]string{"db.example.com": "10.0.0.1", "api.example.com": "1.2.3.4"}, config.GetMap("hosts"))
+	assert.Equal(t, LAYER_FILE, config.Explain("hosts.db.example.com").Source.Layer)
+
+	config.DeleteFromMap("hosts", "api.example.com")
+	content, err = os.ReadFile(config.Path())
+	assert.NoError(t, err)
+	assert.Equal(t, "hosts:\n  db.example.com: 10.0.0.1\n", string(content))
+}
+
+func TestConfigFormatsFollowTheFileExtension(t *testing.T) {
+	files := map[string]string{
+		"app.json": `{"name": "suzy", "server": {"port": 80}}`,
+		"app.toml": "name = \"suzy\"\n[server]\nport = 80\n",
+		"app.env":  "NAME=suzy\nPORT=80\n",
+	}
+	for name, content := range files {
+		file := filepath.Join(t.TempDir(), name)
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+		config := NewConfiguration(name, false, nil)
+		config.Load(file)
+		assert.Equal(t, "suzy", config.Get("name"), name)
+
+		config.Update("name", "q")
+		reloaded := NewConfiguration(name, false, nil)
+		reloaded.Load(file)
+		assert.Equal(t, "q", reloaded.Get("name"), "%s should be written in its own format", name)
+	}
+}
+
+func TestProfilesMergeOverTheBaseSettings(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "suzy.yaml")
+	assert.NoError(
+		t, os.WriteFile(
+			file, []byte("host: localhost\nport: 8080\nprofiles:\n  staging:\n    host: staging.example.com\n"), 0644,
+		),
+	)
+	prodFile := filepath.Join(dir, "suzy.prod.yaml")
+	assert.NoError(t, os.WriteFile(prodFile, []byte("host: example.com\nport: 443\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "suzy.yaml.lock"), nil, 0644))
+
+	config := NewConfiguration("suzy.yaml", false, nil).SetEnvPrefix("SUZY")
+	config.Load(file)
+	assert.Equal(t, "", config.Profile())
+	assert.Equal(t, "localhost", config.Get("host"))
+	assert.Equal(t, []string{"prod", "staging"}, config.Profiles())
+
+	t.Setenv("SUZY_PROFILE", "staging")
+	config.Load(file)
+	assert.Equal(t, "staging", config.Profile())
+	assert.Equal(t, "staging.example.com", config.Get("host"))
+	assert.Equal(t, 8080, config.GetInt("port"), "settings missing from the profile should come from the base")
+	assert.Equal(t, file+" (profiles.staging)", config.Explain("host").Source.Origin)
+
+	config.SetProfile("prod")
+	assert.Equal(t, "example.com", config.Get("host"))
+	assert.Equal(t, 443, config.GetInt("port"))
+	assert.Equal(t, ConfigValueSource{LAYER_PROFILE, prodFile, 443}, *config.Explain("port").Source)
+	assert.Equal(t, file, config.Path(), "writes should go to the base file")
+
+	diffs, err := config.DiffProfiles("staging", "prod")
+	assert.NoError(t, err)
+	assert.Equal(
+		t, []string{"host: staging.example.com -> example.com", "port: 8080 -> 443"},
+		[]string{diffs[0].String(), diffs[1].String()},
+	)
+	assert.Len(t, diffs, 2)
+	_, err = config.DiffProfiles("", "qa")
+	assert.Error(t, err)
+
+	assert.NoError(t, config.SwitchProfile("staging"))
+	assert.Equal(t, "prod", config.Profile(), "the profile set in code should win over the file")
+	settings, err := readSettings(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", settings[PROFILE_KEY])
+	assert.Error(t, config.SwitchProfile("qa"))
+}
+
+func TestValuesExpandReferences(t *testing.T) {
+	t.Setenv("SUZY_TEST_REGION", "eu")
+	state := withFreshState(t)
+	state.homeDir = "/home/suzy"
+	state.dataDir = "/var/lib/suzy"
+	config := newBindTestConfig(
+		t, `
+db:
+  dir: ${state:dataDir}/db
+  path: ${db.dir}/suzy.db
+cache: ~/.cache/suzy
+region: ${env:SUZY_TEST_REGION}
+zone: ${env:SUZY_TEST_ZONE:-${region}-1}
+literal: $${region}
+hosts: ["${region}.example.com"]
+loop: ${ping}
+ping: ${pong}
+pong: ${ping}
+missing: ${nope}
+`,
+	)
+
+	assert.Equal(t, "/var/lib/suzy/db/suzy.db", config.Get("db.path"))
+	assert.Equal(t, "/home/suzy/.cache/suzy", config.Get("cache"))
+	assert.Equal(t, "eu-1", config.Get("zone"))
+	assert.Equal(t, "${region}", config.Get("literal"))
+	assert.Equal(t, []string{"eu.example.com"}, config.GetList("hosts"))
+	assert.Equal(t, map[string]string{"dir": "/var/lib/suzy/db", "path": "/var/lib/suzy/db/suzy.db"}, config.GetMap("db"))
+
+	_, err := config.Resolve("loop")
+	assert.EqualError(t, err, "unable to expand ${ping} in config [loop]: reference cycle ping -> pong -> ping")
+	_, err = config.Resolve("missing")
+	assert.EqualError(t, err, "unable to expand ${nope} in config [missing]: it is unset or empty and has no :-default")
+	assert.Equal(t, "${nope}", config.Get("missing"), "unresolved references should be left as they are")
+
+	expanded, err := config.Expand("${region:-us}/${state:appName:-suzy}")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu/suzy", expanded)
+}
+
+func TestExportImportAndDiff(t *testing.T) {
+	t.Setenv("CONFIG_SECRET_KEY", "export-test-key")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "export.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("name: suzy\nport: 9090\ndata: ${name}-data\n"), 0644))
+	config := NewConfiguration("export.yaml", false, Properties{"port": 8080, "mode": "dev"})
+	config.Load(file)
+	assert.NoError(t, config.UpdateSecret("db.password", "hunter2"))
+
+	exported := config.Export(true)
+	assert.Equal(t, "suzy-data", exported["data"])
+	assert.Equal(t, REDACTED, exported["db"].(map[string]any)["password"])
+	assert.True(t, IsEncrypted(config.Export(false)["db"].(map[string]any)["password"]))
+
+	assert.Contains(
+		t, config.ExportSources(true), ExportedSetting{Key: "mode", Value: "dev", Layer: LAYER_DEFAULTS},
+	)
+	assert.Contains(t, config.ExportSources(true), ExportedSetting{Key: "port", Value: 9090, Layer: LAYER_FILE, Origin: file})
+
+	diffs := make([]string, 0)
+	for _, diff := range config.DiffDefaults() {
+		diffs = append(diffs, diff.String())
+	}
+	assert.Equal(
+		t, []string{
+			"data: (unset) -> suzy-data", "db.password: (unset) -> " + REDACTED, "name: (unset) -> suzy", "port: 8080 -> 9090",
+		}, diffs,
+	)
+
+	redactedExport := filepath.Join(dir, "exported.yaml")
+	out := new(bytes.Buffer)
+	assert.NoError(t, config.WriteExport(out, ExportOptions{Redact: true}))
+	assert.NoError(t, os.WriteFile(redactedExport, out.Bytes(), 0644))
+	other := filepath.Join(dir, "other.yaml")
+	assert.NoError(t, os.WriteFile(other, []byte("name: other\ncolor: red\ndb:\n  password: \""+REDACTED+"\"\n"), 0644))
+
+	fileDiffs, err := config.DiffFile(other)
+	assert.NoError(t, err)
+	assert.Len(t, fileDiffs, 5, "color, data, mode, name and port differ")
+
+	assert.NoError(t, config.Import(other, false))
+	assert.Equal(t, "other", config.Get("name"))
+	assert.Equal(t, "red", config.Get("color"))
+	assert.Equal(t, 9090, config.GetInt("port"))
+	assert.Equal(t, "hunter2", config.Get("db.password"), "redacted values should keep their current value")
+
+	assert.NoError(t, config.Import(redactedExport, true))
+	assert.False(t, config.IsSet("color"), "replace should drop settings missing from the imported file")
+	assert.Equal(t, "suzy", config.Get("name"))
+	assert.Equal(t, "hunter2", config.Get("db.password"))
+}
+
+func TestTypedGetters(t *testing.T) {
+	config := newBindTestConfig(
+		t, `
+retries: 0
+timeout: 5m
+bad_timeout: 300
+max_upload: 10MB
+cache_size: 1.5GiB
+endpoint: https://example.com/api
+relative: /api
+started: 2026-10-18T09:00:00Z
+birthday: "2026-10-18"
+server:
+  host: example.com
+`,
+	)
+
+	assert.Equal(t, 0, GetOr(config, "retries", 3), "a zero that is set should not be replaced by the default")
+	assert.Equal(t, 3, GetOr(config, "missing", 3))
+	assert.Equal(t, 0, config.GetIntWithDefault("retries", 3))
+	assert.Equal(t, 5*time.Minute, GetOr(config, "timeout", time.Second))
+
+	timeout, err := config.GetDuration("bad_timeout")
+	assert.Zero(t, timeout)
+	var valueErr *ConfigValueError
+	assert.ErrorAs(t, err, &valueErr)
+	assert.Equal(t, "bad_timeout", valueErr.Key)
+	assert.EqualError(t, err, "config [bad_timeout] value [300] is not a valid time.Duration: expected a duration with a unit, like 300ms, 5m or 1h30m")
+	_, err = GetAs[int](config, "missing")
+	assert.ErrorIs(t, err, ErrConfigNotSet)
+
+	size, err := config.GetByteSize("max_upload")
+	assert.NoError(t, err)
+	assert.Equal(t, 10*MB, size)
+	size, err = config.GetByteSize("cache_size")
+	assert.NoError(t, err)
+	assert.Equal(t, "1536MiB", size.String())
+	_, err = ParseByteSize("10 parsecs")
+	assert.EqualError(t, err, "unknown unit [parsecs] in byte size [10 parsecs], expected B, KB, MB, GB, TB, KiB, MiB, GiB or TiB")
+
+	endpoint, err := config.GetURL("endpoint")
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", endpoint.Host)
+	_, err = config.GetURL("relative")
+	assert.Error(t, err)
+
+	started, err := config.GetTime("started")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC), started.UTC())
+	birthday, err := config.GetTime("birthday")
+	assert.NoError(t, err)
+	assert.Equal(t, 18, birthday.Day())
+
+	server, err := GetAs[serverConfig](config, "server")
+	assert.NoError(t, err)
+	assert.Equal(t, serverConfig{Host: "example.com", Port: 8080, Timeout: 5 * time.Second}, server)
+}
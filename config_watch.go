@@ -0,0 +1,201 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_watch.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+const DEFAULT_RELOAD_DEBOUNCE = 250 * time.Millisecond
+
+// ConfigValidator checks a freshly loaded configuration before it replaces the current one,
+// e.g. by binding it into a struct with Bind.
+type ConfigValidator func(candidate *Configuration) error
+
+// ConfigWatcher - Reloads a Configuration whenever its file changes
+type ConfigWatcher struct {
+	config   *Configuration
+	path     string
+	paths    []string
+	debounce time.Duration
+	validate ConfigValidator
+	watcher  *fsnotify.Watcher
+	timer    *time.Timer
+	lock     sync.Mutex
+	reload   sync.Mutex
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Watch reloads the configuration whenever one of its layer files changes. Changes arriving within
+// [debounce] of each other are reloaded once. When a file fails to parse, or validate returns an
+// error, the last good settings are kept and a CONFIG_RELOAD_FAILED_EVENT is sent. Otherwise a
+// CONFIG_CHANGED_EVENT listing the changed keys is sent if anything changed.
+func (c *Configuration) Watch(debounce time.Duration, validate ConfigValidator) (*ConfigWatcher, error) {
+	layers := c.loadedLayers()
+	paths := configLayerPaths(layers)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("config [%s] was not loaded from a file so it cannot be watched", c.filename)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// Watch the directories rather than the files so editors that replace the file are noticed
+	for _, path := range paths {
+		if err = watcher.Add(filepath.Dir(path)); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+
+	w := &ConfigWatcher{
+		config:   c,
+		path:     baseLayer(layers).path,
+		paths:    paths,
+		debounce: debounce,
+		validate: validate,
+		watcher:  watcher,
+		done:     make(chan struct{}),
+	}
+	go w.watch()
+	return w, nil
+}
+
+func (w *ConfigWatcher) watch() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if Contains(w.paths, filepath.Clean(event.Name)) && event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				w.schedule()
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			LogError(err, "Error watching config [%s]", w.path)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *ConfigWatcher) schedule() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.debounce, w.Reload)
+}
+
+// Reload loads the file into a new set of settings and swaps them in if they are valid.
+func (w *ConfigWatcher) Reload() {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+	select {
+	case <-w.done:
+		return
+	default:
+	}
+
+	config := w.config
+	loaded, err := config.load(true)
+	if err != nil {
+		w.failed(err)
+		return
+	}
+	if w.validate != nil {
+		candidate := config.clone()
+		candidate.v, candidate.layers, candidate.activeProfile = loaded.v, loaded.layers, loaded.profile
+		if err = w.validate(candidate); err != nil {
+			w.failed(err)
+			return
+		}
+	}
+
+	changed := changedSettings(config.Viper(), loaded.v)
+	config.swap(loaded.v, loaded.layers, loaded.profile)
+	if len(changed) > 0 {
+		log.Infof("Reloaded config from [%s]", strings.Join(configLayerPaths(loaded.layers), ", "))
+		EventBus.Send(NewConfigChangedEvent(w.path, changed))
+	}
+}
+
+func (w *ConfigWatcher) failed(err error) {
+	LogError(err, "Unable to reload config [%s], keeping the last good config", w.path)
+	EventBus.Send(NewConfigReloadFailedEvent(w.path, err))
+}
+
+// Stop stops watching the file. It is safe to call more than once.
+func (w *ConfigWatcher) Stop() {
+	w.stopOnce.Do(
+		func() {
+			close(w.done)
+			w.lock.Lock()
+			if w.timer != nil {
+				w.timer.Stop()
+			}
+			w.lock.Unlock()
+			CheckError(w.watcher.Close(), "Error closing the watcher for config [%s]", w.path)
+		},
+	)
+}
+
+// changedSettings returns the sorted keys that were added, removed or changed between two sets of settings.
+func changedSettings(before *viper.Viper, after *viper.Viper) []string {
+	changed := make([]string, 0)
+	for _, key := range after.AllKeys() {
+		if !reflect.DeepEqual(before.Get(key), after.Get(key)) {
+			changed = append(changed, key)
+		}
+	}
+	for _, key := range before.AllKeys() {
+		if !after.IsSet(key) && !Contains(changed, key) {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
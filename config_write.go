@@ -0,0 +1,228 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_write.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// DEFAULT_CONFIG_BACKUPS is how many backups of a config file are kept unless SetBackupCount says otherwise
+const DEFAULT_CONFIG_BACKUPS = 5
+
+const configBackupTimeFormat = "20060102T150405.000000000"
+
+// SetBackupCount sets how many timestamped backups are kept of the config file, newest first.
+// Zero turns backups off.
+func (c *Configuration) SetBackupCount(count int) *Configuration {
+	c.backupCount = count
+	return c
+}
+
+// Backups returns the backups of the config file, newest first.
+func (c *Configuration) Backups() []string {
+	return configBackups(c.Path())
+}
+
+// Restore replaces the config file with its [n]th newest backup, 1 being the newest, and reloads it.
+// The file being replaced is backed up first so a restore can itself be undone.
+func (c *Configuration) Restore(n int) error {
+	path := c.Path()
+	backups := configBackups(path)
+	if n < 1 || n > len(backups) {
+		return fmt.Errorf("there is no backup %d of config [%s], %d available", n, path, len(backups))
+	}
+	content, err := os.ReadFile(backups[n-1])
+	if err != nil {
+		return err
+	}
+
+	err = withFileLock(
+		path, func() error {
+			if err := c.backup(path); err != nil {
+				return err
+			}
+			return writeFileAtomically(
+				path, func(tmp string) error {
+					return writeAndSync(tmp, content)
+				},
+			)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to restore config [%s] from [%s]: %w", path, backups[n-1], err)
+	}
+	return c.readConfig()
+}
+
+// writeConfig writes the settings that belong in the file at path to it while holding its lock,
+// backing up the current file first.
+func (c *Configuration) writeConfig(path string) error {
+	settings := c.ownSettings(path)
+	return c.editFile(
+		path, func(current map[string]any) []yamlEdit {
+			return updateEdits(current, settings)
+		},
+	)
+}
+
+// ownSettings returns the settings that belong in the file at path: the values it supplies and the
+// values set in code or by defaults. Values supplied by other files, the active profile, env vars
+// or flags are left where they are.
+func (c *Configuration) ownSettings(path string) map[string]any {
+	own := make(map[string]any)
+	walkSettings(
+		nil, c.Viper().AllSettings(), func(parts []string, value any) {
+			key := strings.Join(parts, ".")
+			sources := c.sources(key)
+			if len(sources) > 0 && sources[0].Layer != LAYER_DEFAULTS && sources[0].Origin != path &&
+				fmt.Sprint(sources[0].Value) == fmt.Sprint(value) {
+				return
+			}
+			setSetting(own, key, value)
+		},
+	)
+	return own
+}
+
+func (c *Configuration) backup(path string) error {
+	if c.backupCount <= 0 || !isFile(path) {
+		return nil
+	}
+	backup := fmt.Sprintf("%s.bak.%s", path, time.Now().Format(configBackupTimeFormat))
+	if err := copyFile(path, backup); err != nil {
+		return fmt.Errorf("unable to back up config [%s]: %w", path, err)
+	}
+	for _, old := range configBackups(path)[min(c.backupCount, len(configBackups(path))):] {
+		LogError(os.Remove(old), "Unable to remove old config backup [%s]", old)
+	}
+	return nil
+}
+
+func configBackups(path string) []string {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+	prefix := filepath.Base(path) + ".bak."
+	backups := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) {
+			backups = append(backups, filepath.Join(filepath.Dir(path), entry.Name()))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups
+}
+
+// writeFileAtomically has write fill a temp file next to path, which then replaces path with a
+// rename so readers only ever see the old or the new file.
+func writeFileAtomically(path string, write func(tmp string) error) error {
+	tmp := filepath.Join(
+		filepath.Dir(path),
+		fmt.Sprintf(".%s.%d-%d.tmp.%s", filepath.Base(path), os.Getpid(), time.Now().UnixNano(), configFileType(path)),
+	)
+	if err := write(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		_ = os.Chmod(tmp, info.Mode().Perm())
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func writeAndSync(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = f.Write(content); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// withFileLock runs fn while holding an exclusive advisory lock on path, shared by every process
+// that writes the file through this package.
+func withFileLock(path string, fn func() error) error {
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return fmt.Errorf("unable to lock config [%s]: %w", path, err)
+	}
+	defer unlock()
+	return fn()
+}
+
+// marshalSettings encodes settings as a config file of fileType.
+func marshalSettings(settings map[string]any, fileType string) ([]byte, error) {
+	switch fileType {
+	case "yaml", "yml":
+		return yaml.Marshal(settings)
+	case "json":
+		return json.MarshalIndent(settings, "", "  ")
+	case "toml":
+		return toml.Marshal(settings)
+	}
+	fs := afero.NewMemMapFs()
+	v := viper.New()
+	v.SetFs(fs)
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+	file := "/config." + fileType
+	if err := v.WriteConfigAs(file); err != nil {
+		return nil, err
+	}
+	return afero.ReadFile(fs, file)
+}
+
+// configFileType returns the viper config type for path: its extension if viper supports it, otherwise yaml.
+func configFileType(path string) string {
+	if ext := strings.TrimPrefix(filepath.Ext(path), "."); Contains(viper.SupportedExts, ext) {
+		return ext
+	}
+	return "yaml"
+}
@@ -0,0 +1,375 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: config_yaml.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlEdit - A change to a single key of a yaml config file. The path holds the key's segments so
+// map keys containing dots, such as host names, stay a single key.
+type yamlEdit struct {
+	path   []string
+	value  any
+	delete bool
+	append bool // value is a list whose new items are appended to the list at key, if there is one
+}
+
+// writeEdit saves a single changed key to Path.
+func (c *Configuration) writeEdit(edit yamlEdit) error {
+	return c.editFile(
+		c.Path(), func(map[string]any) []yamlEdit {
+			return []yamlEdit{edit}
+		},
+	)
+}
+
+// editFile applies the edits worked out from the file's current settings while holding its lock,
+// backing the file up first. YAML files are edited in place so comments, key order and the
+// formatting of untouched keys survive. Other formats are rewritten from the edited settings.
+func (c *Configuration) editFile(path string, edits func(current map[string]any) []yamlEdit) error {
+	return withFileLock(
+		path, func() error {
+			content, err := os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			current := make(map[string]any)
+			if len(bytes.TrimSpace(content)) > 0 {
+				if current, err = parseSettings(content, configFileType(path)); err != nil {
+					return fmt.Errorf("unable to parse config [%s]: %w", path, err)
+				}
+			}
+			changes := edits(current)
+			if len(changes) == 0 && isFile(path) {
+				return nil
+			}
+
+			var updated []byte
+			if isYamlFile(path) {
+				updated, err = editYaml(content, changes...)
+			} else {
+				for _, change := range changes {
+					change.applyTo(current)
+				}
+				updated, err = marshalSettings(current, configFileType(path))
+			}
+			if err != nil {
+				return err
+			}
+			if err = c.backup(path); err != nil {
+				return err
+			}
+			return writeFileAtomically(
+				path, func(tmp string) error {
+					return writeAndSync(tmp, updated)
+				},
+			)
+		},
+	)
+}
+
+// updateEdits returns the edits that set every value in settings that the current settings lack
+// or hold a different value for.
+func updateEdits(current map[string]any, settings map[string]any) []yamlEdit {
+	edits := make([]yamlEdit, 0)
+	walkSettings(
+		nil, settings, func(parts []string, value any) {
+			path := filePath(current, parts)
+			if existing, ok := settingAt(current, path); !ok || !reflect.DeepEqual(existing, value) {
+				edits = append(edits, yamlEdit{path: path, value: value})
+			}
+		},
+	)
+	return edits
+}
+
+// replaceEdits returns the edits that turn the current settings into settings.
+func replaceEdits(current map[string]any, settings map[string]any) []yamlEdit {
+	edits := make([]yamlEdit, 0)
+	walkSettings(
+		nil, current, func(path []string, _ any) {
+			if _, ok := lookupSetting(settings, strings.Join(path, ".")); !ok {
+				edits = append(edits, yamlEdit{path: path, delete: true})
+			}
+		},
+	)
+	return append(edits, updateEdits(current, settings)...)
+}
+
+// walkSettings calls visit with the path and value of every leaf of settings, in key order. Empty
+// maps count as leaves.
+func walkSettings(prefix []string, settings map[string]any, visit func(path []string, value any)) {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		path := append(append([]string{}, prefix...), key)
+		if nested, ok := settings[key].(map[string]any); ok && len(nested) > 0 {
+			walkSettings(path, nested, visit)
+		} else {
+			visit(path, settings[key])
+		}
+	}
+}
+
+// settingAt finds the setting at path.
+func settingAt(settings map[string]any, path []string) (any, bool) {
+	var current any = settings
+	for _, part := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// filePath joins back the segments of parts that make up a key containing dots in settings, so
+// values from viper, which splits such keys, land on the key the file already has.
+func filePath(settings map[string]any, parts []string) []string {
+	path := make([]string, 0, len(parts))
+	node := settings
+	for i := 0; i < len(parts); {
+		j := len(parts)
+		for ; j > i+1; j-- {
+			if _, ok := node[strings.Join(parts[i:j], ".")]; ok {
+				break
+			}
+		}
+		key := strings.Join(parts[i:j], ".")
+		path = append(path, key)
+		node, _ = node[key].(map[string]any)
+		i = j
+	}
+	return path
+}
+
+// keyPath splits a dotted property name into the segments of a yamlEdit path.
+func keyPath(propertyName string) []string {
+	return strings.Split(propertyName, ".")
+}
+
+func isYamlFile(path string) bool {
+	fileType := configFileType(path)
+	return fileType == "yaml" || fileType == "yml"
+}
+
+// editYaml applies the edits to the yaml document in content.
+func editYaml(content []byte, edits ...yamlEdit) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the top level of the config file is not a map")
+	}
+
+	for _, edit := range edits {
+		if err := edit.apply(root); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(yamlIndent(content))
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (e yamlEdit) apply(root *yaml.Node) error {
+	parts := e.path
+	node := root
+	for _, part := range parts[:len(parts)-1] {
+		child := yamlMappingValue(node, part)
+		switch {
+		case child == nil && e.delete:
+			return nil
+		case child == nil:
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, yamlKey(part), child)
+		case child.Kind != yaml.MappingNode && e.delete:
+			return nil
+		case child.Kind != yaml.MappingNode:
+			replaceYamlValue(child, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+		}
+		node = child
+	}
+
+	name := parts[len(parts)-1]
+	if e.delete {
+		for i := 0; i < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, name) {
+				node.Content = append(node.Content[:i], node.Content[i+2:]...)
+				return nil
+			}
+		}
+		return nil
+	}
+
+	current := yamlMappingValue(node, name)
+	if e.append && current != nil && current.Kind == yaml.SequenceNode {
+		return appendYamlItems(current, e.value)
+	}
+
+	value := new(yaml.Node)
+	if err := value.Encode(e.value); err != nil {
+		return fmt.Errorf("unable to encode the value of [%s]: %w", strings.Join(e.path, "."), err)
+	}
+	if current == nil {
+		node.Content = append(node.Content, yamlKey(name), value)
+	} else {
+		replaceYamlValue(current, value)
+	}
+	return nil
+}
+
+// applyTo makes the edit to parsed settings, for formats that are not edited in place.
+func (e yamlEdit) applyTo(settings map[string]any) {
+	node := settings
+	for _, part := range e.path[:len(e.path)-1] {
+		part = strings.ToLower(part)
+		child, ok := node[part].(map[string]any)
+		if !ok {
+			if e.delete {
+				return
+			}
+			child = make(map[string]any)
+			node[part] = child
+		}
+		node = child
+	}
+
+	name := strings.ToLower(e.path[len(e.path)-1])
+	switch {
+	case e.delete:
+		delete(node, name)
+	case e.append && node[name] != nil:
+		list := cast.ToStringSlice(node[name])
+		for _, item := range cast.ToStringSlice(e.value) {
+			if !Contains(list, item) {
+				list = append(list, item)
+			}
+		}
+		node[name] = list
+	default:
+		node[name] = e.value
+	}
+}
+
+// appendYamlItems adds the items of list missing from the sequence node, leaving the existing items untouched.
+func appendYamlItems(sequence *yaml.Node, list any) error {
+	existing := make([]string, 0, len(sequence.Content))
+	for _, item := range sequence.Content {
+		existing = append(existing, item.Value)
+	}
+	for _, item := range cast.ToStringSlice(list) {
+		if Contains(existing, item) {
+			continue
+		}
+		node := new(yaml.Node)
+		if err := node.Encode(item); err != nil {
+			return err
+		}
+		sequence.Content = append(sequence.Content, node)
+	}
+	return nil
+}
+
+// yamlMappingValue returns the value of key in the mapping node, matching keys case insensitively like viper does.
+func yamlMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, key) {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func yamlKey(name string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+}
+
+// replaceYamlValue swaps the content of current for value, keeping current's comments and, for
+// scalars of the same type, its quoting style.
+func replaceYamlValue(current *yaml.Node, value *yaml.Node) {
+	if current.Kind == yaml.ScalarNode && value.Kind == yaml.ScalarNode && current.Tag == value.Tag {
+		value.Style = current.Style
+	}
+	value.HeadComment = current.HeadComment
+	value.LineComment = current.LineComment
+	value.FootComment = current.FootComment
+	*current = *value
+}
+
+// yamlIndent guesses the indentation of a yaml document from its least indented nested line.
+func yamlIndent(content []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		spaces := len(line) - len(trimmed)
+		if spaces == 0 || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indent == 0 || spaces < indent {
+			indent = spaces
+		}
+	}
+	if indent < 2 {
+		return 2
+	}
+	return indent
+}
@@ -167,6 +167,17 @@ func (c *PersistenceContext) OpenDB() {
 	c.PopulateReferenceData()
 }
 
+func (c *PersistenceContext) Close() error {
+	if c.DB == nil {
+		return nil
+	}
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
 func (c *PersistenceContext) InitDB() {
 	CheckError(
 		c.DB.AutoMigrate(
@@ -178,33 +189,64 @@ func (c *PersistenceContext) InitDB() {
 }
 
 func (c *PersistenceContext) Save(value any) {
-	CheckError(c.DB.Save(value).Error, "Error storing %T information to db!", value)
+	CheckError(c.SaveE(value), "Error storing %T information to db!", value)
 }
 
 func (c *PersistenceContext) SaveOmitting(value any, omitted ...string) {
-	CheckError(
-		c.DB.Session(&gorm.Session{}).Omit(omitted...).Save(value).Error,
-		"Error storing %T information to db!",
-		value,
-	)
+	CheckError(c.SaveOmittingE(value, omitted...), "Error storing %T information to db!", value)
 }
 
 func (c *PersistenceContext) SaveFull(value any) {
-	CheckError(
-		c.DB.Session(&gorm.Session{FullSaveAssociations: true}).Save(value).Error,
-		"Error storing %T information to db!",
-		value,
-	)
+	CheckError(c.SaveFullE(value), "Error storing %T information to db!", value)
 }
 
 func (c *PersistenceContext) Create(value any) {
-	CheckError(
-		c.DB.Session(&gorm.Session{FullSaveAssociations: true}).Create(value).Error,
-		"Error storing %T information to db!",
-		value,
+	CheckError(c.CreateE(value), "Error storing %T information to db!", value)
+}
+
+// SaveE updates value, or inserts it if it has no primary key yet, and returns any error. See
+// Repository for reads and deletes.
+func (c *PersistenceContext) SaveE(value any) error {
+	return c.store(value, func(db *gorm.DB) *gorm.DB { return db.Save(value) })
+}
+
+// SaveOmittingE saves value without the omitted fields and associations and returns any error.
+func (c *PersistenceContext) SaveOmittingE(value any, omitted ...string) error {
+	return c.store(
+		value, func(db *gorm.DB) *gorm.DB {
+			return db.Session(&gorm.Session{}).Omit(omitted...).Save(value)
+		},
 	)
 }
 
+// SaveFullE saves value along with all of its associations and returns any error.
+func (c *PersistenceContext) SaveFullE(value any) error {
+	return c.store(
+		value, func(db *gorm.DB) *gorm.DB {
+			return db.Session(&gorm.Session{FullSaveAssociations: true}).Save(value)
+		},
+	)
+}
+
+// CreateE inserts value along with all of its associations and returns any error.
+func (c *PersistenceContext) CreateE(value any) error {
+	return c.store(
+		value, func(db *gorm.DB) *gorm.DB {
+			return db.Session(&gorm.Session{FullSaveAssociations: true}).Create(value)
+		},
+	)
+}
+
+func (c *PersistenceContext) store(value any, write func(db *gorm.DB) *gorm.DB) error {
+	if c.DB == nil {
+		return ErrDBNotOpen
+	}
+	if err := write(c.DB).Error; err != nil {
+		return fmt.Errorf("unable to store %T: %w", value, err)
+	}
+	return nil
+}
+
 func (c *PersistenceContext) OmitFields(omitted ...string) *gorm.DB {
 	return c.DB.Omit(omitted...)
 }
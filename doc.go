@@ -65,6 +65,7 @@ func GenMarkdownCustom(
 ) error {
 	cmd.InitDefaultHelpCmd()
 	cmd.InitDefaultHelpFlag()
+	inheritAutoGenTag(cmd)
 
 	buf := new(bytes.Buffer)
 	name := cmd.CommandPath()
@@ -96,22 +97,9 @@ func GenMarkdownCustom(
 			link := pname + ".md"
 			link = strings.ReplaceAll(link, " ", "_")
 			buf.WriteString(fmt.Sprintf("* [%s](%s)\t - %s\n", pname, linkHandler(filename, link), parent.Short))
-			cmd.VisitParents(
-				func(c *cobra.Command) {
-					if c.DisableAutoGenTag {
-						cmd.DisableAutoGenTag = c.DisableAutoGenTag
-					}
-				},
-			)
 		}
 
-		children := cmd.Commands()
-		sort.Sort(byName(children))
-
-		for _, child := range children {
-			if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
-				continue
-			}
+		for _, child := range availableChildren(cmd) {
 			cname := name + " " + child.Name()
 			link := cname + ".md"
 			link = strings.ReplaceAll(link, " ", "_")
@@ -130,31 +118,83 @@ func GenMarkdownTreeCustom(
 	dir string,
 	filePrepender func(string) string,
 	linkHandler func(string, string) string,
+) error {
+	return genDocTree(
+		cmd, dir, "_", ".md", func(c *cobra.Command, w io.Writer, filename string) error {
+			if _, err := io.WriteString(w, filePrepender(filename)); err != nil {
+				return err
+			}
+			return GenMarkdownCustom(c, w, filename, linkHandler)
+		},
+	)
+}
+
+// genDocTree walks the command tree depth first and writes one file per available command
+// into dir. The file name is the command path joined with separator plus the given extension.
+func genDocTree(
+	cmd *cobra.Command,
+	dir string,
+	separator string,
+	extension string,
+	gen func(cmd *cobra.Command, w io.Writer, filename string) error,
+) error {
+	return walkDocTree(
+		cmd, dir, separator, extension, func(c *cobra.Command, filename string) error {
+			f, err := os.Create(filename)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return gen(c, f, filename)
+		},
+	)
+}
+
+// walkDocTree calls visit, children first, with every available command and the file its docs belong in.
+func walkDocTree(
+	cmd *cobra.Command,
+	dir string,
+	separator string,
+	extension string,
+	visit func(cmd *cobra.Command, filename string) error,
 ) error {
 	for _, c := range cmd.Commands() {
 		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
 			continue
 		}
-		if err := GenMarkdownTreeCustom(c, dir, filePrepender, linkHandler); err != nil {
+		if err := walkDocTree(c, dir, separator, extension, visit); err != nil {
 			return err
 		}
 	}
 
-	basename := strings.ReplaceAll(cmd.CommandPath(), " ", "_") + ".md"
-	filename := filepath.Join(dir, basename)
-	f, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	basename := strings.ReplaceAll(cmd.CommandPath(), " ", separator) + extension
+	return visit(cmd, filepath.Join(dir, basename))
+}
 
-	if _, err := io.WriteString(f, filePrepender(filename)); err != nil {
-		return err
-	}
-	if err := GenMarkdownCustom(cmd, f, filename, linkHandler); err != nil {
-		return err
+// inheritAutoGenTag copies a DisableAutoGenTag set on any parent down to the command.
+func inheritAutoGenTag(cmd *cobra.Command) {
+	cmd.VisitParents(
+		func(c *cobra.Command) {
+			if c.DisableAutoGenTag {
+				cmd.DisableAutoGenTag = c.DisableAutoGenTag
+			}
+		},
+	)
+}
+
+// availableChildren returns the sorted sub commands that should be documented.
+func availableChildren(cmd *cobra.Command) []*cobra.Command {
+	children := cmd.Commands()
+	sort.Sort(byName(children))
+
+	available := make([]*cobra.Command, 0, len(children))
+	for _, child := range children {
+		if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		available = append(available, child)
 	}
-	return nil
+	return available
 }
 
 // Test to see if we have a reason to print See Also information in docs
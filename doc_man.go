@@ -0,0 +1,193 @@
+/*
+ *
+ * Reproduced and modified from https://github.com/spf13/cobra
+ *
+ * Copyright 2013-2022 The Cobra Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, analyzer
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Filename: doc_man.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ *
+ */
+
+package golang_utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ManHeader holds the values written to the .TH line of generated man pages.
+// Empty values are filled in from the command being documented.
+type ManHeader struct {
+	Title   string
+	Section string
+	Date    *time.Time
+	Source  string
+	Manual  string
+}
+
+// GenManCustom creates a roff formatted man page for the command.
+func GenManCustom(cmd *cobra.Command, w io.Writer, header *ManHeader) error {
+	cmd.InitDefaultHelpCmd()
+	cmd.InitDefaultHelpFlag()
+	inheritAutoGenTag(cmd)
+
+	if header == nil {
+		header = &ManHeader{}
+	}
+	h := *header
+	fillManHeader(cmd, &h)
+
+	buf := new(bytes.Buffer)
+	dashedPath := strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+
+	buf.WriteString(
+		fmt.Sprintf(
+			".TH \"%s\" \"%s\" \"%s\" \"%s\" \"%s\"\n",
+			h.Title, h.Section, h.Date.Format("Jan 2006"), h.Source, h.Manual,
+		),
+	)
+	buf.WriteString(".nh\n.ad l\n")
+
+	buf.WriteString(".SH NAME\n")
+	buf.WriteString(fmt.Sprintf("%s \\- %s\n", roffEscape(dashedPath), roffEscape(cmd.Short)))
+
+	buf.WriteString(".SH SYNOPSIS\n")
+	buf.WriteString(fmt.Sprintf("\\fB%s\\fP\n", roffEscape(cmd.UseLine())))
+
+	buf.WriteString(".SH DESCRIPTION\n")
+	description := cmd.Long
+	if len(description) == 0 {
+		description = cmd.Short
+	}
+	buf.WriteString(roffText(description) + "\n")
+
+	manPrintFlags(buf, "OPTIONS", cmd.NonInheritedFlags())
+	manPrintFlags(buf, "OPTIONS INHERITED FROM PARENT COMMANDS", cmd.InheritedFlags())
+
+	if len(cmd.Example) > 0 {
+		buf.WriteString(".SH EXAMPLE\n.PP\n.RS\n.nf\n")
+		buf.WriteString(roffText(cmd.Example) + "\n")
+		buf.WriteString(".fi\n.RE\n")
+	}
+
+	if hasSeeAlso(cmd) {
+		seeAlso := make([]string, 0)
+		if cmd.HasParent() {
+			parentPath := strings.ReplaceAll(cmd.Parent().CommandPath(), " ", "-")
+			seeAlso = append(seeAlso, fmt.Sprintf("\\fB%s(%s)\\fP", roffEscape(parentPath), h.Section))
+		}
+		for _, child := range availableChildren(cmd) {
+			seeAlso = append(
+				seeAlso,
+				fmt.Sprintf("\\fB%s-%s(%s)\\fP", roffEscape(dashedPath), roffEscape(child.Name()), h.Section),
+			)
+		}
+		buf.WriteString(".SH SEE ALSO\n")
+		buf.WriteString(strings.Join(seeAlso, ", ") + "\n")
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// GenManTreeCustom writes a man page for the command and every available sub command into dir.
+// Pages are named after the dashed command path with the header section as extension (e.g. app-sub.1).
+func GenManTreeCustom(cmd *cobra.Command, dir string, header *ManHeader) error {
+	section := "1"
+	if header != nil && header.Section != "" {
+		section = header.Section
+	}
+	return genDocTree(
+		cmd, dir, "-", "."+section, func(c *cobra.Command, w io.Writer, _ string) error {
+			var h *ManHeader
+			if header != nil {
+				headerCopy := *header
+				h = &headerCopy
+			}
+			return GenManCustom(c, w, h)
+		},
+	)
+}
+
+func fillManHeader(cmd *cobra.Command, header *ManHeader) {
+	if header.Title == "" {
+		header.Title = strings.ToUpper(strings.ReplaceAll(cmd.CommandPath(), " ", "\\-"))
+	}
+	if header.Section == "" {
+		header.Section = "1"
+	}
+	if header.Date == nil {
+		now := time.Now()
+		header.Date = &now
+	}
+}
+
+func manPrintFlags(buf *bytes.Buffer, title string, flags *pflag.FlagSet) {
+	if !flags.HasAvailableFlags() {
+		return
+	}
+	buf.WriteString(".SH " + title + "\n")
+	flags.VisitAll(
+		func(flag *pflag.Flag) {
+			if len(flag.Deprecated) > 0 || flag.Hidden {
+				return
+			}
+			format := ""
+			if len(flag.Shorthand) > 0 && len(flag.ShorthandDeprecated) == 0 {
+				format = fmt.Sprintf("\\fB\\-%s\\fP, \\fB\\-\\-%s\\fP", flag.Shorthand, roffEscape(flag.Name))
+			} else {
+				format = fmt.Sprintf("\\fB\\-\\-%s\\fP", roffEscape(flag.Name))
+			}
+			if len(flag.NoOptDefVal) > 0 {
+				format += "["
+			}
+			if flag.Value.Type() == "string" {
+				format += fmt.Sprintf("=%q", roffEscape(flag.DefValue))
+			} else {
+				format += "=" + roffEscape(flag.DefValue)
+			}
+			if len(flag.NoOptDefVal) > 0 {
+				format += "]"
+			}
+			buf.WriteString(".TP\n" + format + "\n" + roffText(flag.Usage) + "\n")
+		},
+	)
+}
+
+// roffEscape escapes characters that have a special meaning inside a roff line.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	return strings.ReplaceAll(s, "-", "\\-")
+}
+
+// roffText escapes a block of text, protecting lines that would otherwise be read as roff requests.
+func roffText(s string) string {
+	lines := strings.Split(strings.TrimRight(roffEscape(s), "\n"), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = "\\&" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
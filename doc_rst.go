@@ -0,0 +1,142 @@
+/*
+ *
+ * Reproduced and modified from https://github.com/spf13/cobra
+ *
+ * Copyright 2013-2022 The Cobra Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, analyzer
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Filename: doc_rst.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ *
+ */
+
+package golang_utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func printOptionsReST(buf *bytes.Buffer, cmd *cobra.Command) {
+	flags := cmd.NonInheritedFlags()
+	flags.SetOutput(buf)
+	if flags.HasAvailableFlags() {
+		buf.WriteString("Options\n")
+		buf.WriteString("~~~~~~~\n\n::\n\n")
+		flags.PrintDefaults()
+		buf.WriteString("\n")
+	}
+
+	parentFlags := cmd.InheritedFlags()
+	parentFlags.SetOutput(buf)
+	if parentFlags.HasAvailableFlags() {
+		buf.WriteString("Options inherited from parent commands\n")
+		buf.WriteString("~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~\n\n::\n\n")
+		parentFlags.PrintDefaults()
+		buf.WriteString("\n")
+	}
+}
+
+// DefaultReSTLinkHandler links to other pages with a sphinx :ref: role.
+func DefaultReSTLinkHandler(name, ref string) string {
+	return fmt.Sprintf(":ref:`%s <%s>`", name, ref)
+}
+
+// GenReSTCustom creates reStructuredText output. The linkHandler receives the command name and
+// the reference label of the page being linked to.
+func GenReSTCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string, string) string) error {
+	cmd.InitDefaultHelpCmd()
+	cmd.InitDefaultHelpFlag()
+	inheritAutoGenTag(cmd)
+
+	buf := new(bytes.Buffer)
+	name := cmd.CommandPath()
+	ref := strings.ReplaceAll(name, " ", "_")
+
+	buf.WriteString(".. _" + ref + ":\n\n")
+	buf.WriteString(name + "\n")
+	buf.WriteString(strings.Repeat("-", len(name)) + "\n\n")
+	buf.WriteString(cmd.Short + "\n\n")
+	if len(cmd.Long) > 0 {
+		buf.WriteString("Synopsis\n")
+		buf.WriteString("~~~~~~~~\n\n")
+		buf.WriteString(cmd.Long + "\n\n")
+	}
+
+	if cmd.Runnable() {
+		buf.WriteString(fmt.Sprintf("::\n\n  %s\n\n", cmd.UseLine()))
+	}
+
+	if len(cmd.Example) > 0 {
+		buf.WriteString("Examples\n")
+		buf.WriteString("~~~~~~~~\n\n")
+		buf.WriteString(fmt.Sprintf("::\n\n%s\n\n", indentReST(cmd.Example, "  ")))
+	}
+
+	printOptionsReST(buf, cmd)
+
+	if hasSeeAlso(cmd) {
+		buf.WriteString("SEE ALSO\n")
+		buf.WriteString("~~~~~~~~\n\n")
+		if cmd.HasParent() {
+			parent := cmd.Parent()
+			pname := parent.CommandPath()
+			pref := strings.ReplaceAll(pname, " ", "_")
+			buf.WriteString(fmt.Sprintf("* %s \t - %s\n", linkHandler(pname, pref), parent.Short))
+		}
+
+		for _, child := range availableChildren(cmd) {
+			cname := name + " " + child.Name()
+			cref := strings.ReplaceAll(cname, " ", "_")
+			buf.WriteString(fmt.Sprintf("* %s \t - %s\n", linkHandler(cname, cref), child.Short))
+		}
+		buf.WriteString("\n")
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// GenReSTTreeCustom is the reStructuredText counterpart of GenMarkdownTreeCustom.
+func GenReSTTreeCustom(
+	cmd *cobra.Command,
+	dir string,
+	filePrepender func(string) string,
+	linkHandler func(string, string) string,
+) error {
+	return genDocTree(
+		cmd, dir, "_", ".rst", func(c *cobra.Command, w io.Writer, filename string) error {
+			if _, err := io.WriteString(w, filePrepender(filename)); err != nil {
+				return err
+			}
+			return GenReSTCustom(c, w, linkHandler)
+		},
+	)
+}
+
+// indentReST indents every line so a block is rendered as a literal block.
+func indentReST(s, indentation string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indentation + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,272 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: doc_site.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// CONFIG_KEY_ANNOTATION - Flag annotation naming the configuration property the flag sets
+	CONFIG_KEY_ANNOTATION = "golang_utils_config_key"
+	// ENV_VAR_ANNOTATION - Flag annotation naming the environment variable that can be used instead of the flag
+	ENV_VAR_ANNOTATION = "golang_utils_env_var"
+)
+
+// FlagIndexEntry - A flag and every command that accepts it
+type FlagIndexEntry struct {
+	Name      string
+	Shorthand string
+	Usage     string
+	ConfigKey string
+	EnvVar    string
+	Commands  []CommandRef
+}
+
+// DocSite - The model handed to the single page and static site templates
+type DocSite struct {
+	Root     *CommandModel
+	Commands []*CommandModel // Depth first, parents before children
+	Flags    []FlagIndexEntry
+}
+
+// NewDocSite builds the model for every available command in the tree and the flag index.
+func NewDocSite(cmd *cobra.Command) *DocSite {
+	site := &DocSite{Root: NewCommandModel(cmd)}
+	site.collect(cmd)
+	site.Flags = buildFlagIndex(site.Commands)
+	return site
+}
+
+func (s *DocSite) collect(cmd *cobra.Command) {
+	s.Commands = append(s.Commands, NewCommandModel(cmd))
+	for _, child := range availableChildren(cmd) {
+		s.collect(child)
+	}
+}
+
+func buildFlagIndex(commands []*CommandModel) []FlagIndexEntry {
+	entries := make(map[string]*FlagIndexEntry)
+	for _, command := range commands {
+		for _, flag := range append(append([]FlagModel{}, command.Flags...), command.InheritedFlags...) {
+			entry, ok := entries[flag.Name]
+			if !ok {
+				entry = &FlagIndexEntry{Name: flag.Name, Shorthand: flag.Shorthand, Usage: flag.Usage}
+				entries[flag.Name] = entry
+			}
+			if entry.ConfigKey == "" {
+				entry.ConfigKey = flag.ConfigKey
+			}
+			if entry.EnvVar == "" {
+				entry.EnvVar = flag.EnvVar
+			}
+			entry.Commands = append(entry.Commands, command.CommandRef)
+		}
+	}
+
+	index := make([]FlagIndexEntry, 0, len(entries))
+	for _, entry := range entries {
+		index = append(index, *entry)
+	}
+	sort.Slice(index, func(i, j int) bool { return index[i].Name < index[j].Name })
+	return index
+}
+
+func flagAnnotation(flag *pflag.Flag, key string) string {
+	if values := flag.Annotations[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func docSiteFuncs() template.FuncMap {
+	funcs := TemplateFuncs()
+	funcs["indent"] = func(depth int) string { return strings.Repeat("  ", depth) }
+	funcs["cell"] = func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+	}
+	return funcs
+}
+
+const singlePageMarkdownTemplate = `# {{ .Root.Path }}
+
+{{ .Root.Short }}
+
+## Table of Contents
+
+{{ range .Commands }}{{ indent (len .Parents) }}- [{{ .Path }}](#{{ .Ref }})
+{{ end }}- [Flag Index](#flag-index)
+{{ range .Commands }}
+<a id="{{ .Ref }}"></a>
+
+## {{ .Path }}
+
+{{ .Short }}
+{{ if .Long }}
+{{ .Long }}
+{{ end }}{{ if .Runnable }}
+` + "```" + `
+{{ .UseLine }}
+` + "```" + `
+{{ end }}{{ if .Example }}
+**Examples**
+
+` + "```" + `
+{{ .Example }}
+` + "```" + `
+{{ end }}{{ if .Flags }}
+| Flag | Type | Default | Description |
+|------|------|---------|-------------|
+{{ range .Flags }}| ` + "`--{{ .Name }}`" + `{{ if .Shorthand }}, ` + "`-{{ .Shorthand }}`" + `{{ end }} | {{ .Type }} | {{ cell .Default }} | {{ cell .Usage }} |
+{{ end }}{{ end }}{{ if .Children }}
+**Sub commands:** {{ range $i, $c := .Children }}{{ if $i }}, {{ end }}[{{ $c.Name }}](#{{ $c.Ref }}){{ end }}
+{{ end }}{{ end }}
+<a id="flag-index"></a>
+
+## Flag Index
+
+| Flag | Accepted by | Config key | Environment variable |
+|------|-------------|------------|----------------------|
+{{ range .Flags }}| ` + "`--{{ .Name }}`" + `{{ if .Shorthand }}, ` + "`-{{ .Shorthand }}`" + `{{ end }} | {{ range $i, $c := .Commands }}{{ if $i }}, {{ end }}[{{ $c.Path }}](#{{ $c.Ref }}){{ end }} | {{ .ConfigKey }} | {{ .EnvVar }} |
+{{ end }}`
+
+// GenSinglePageMarkdown writes the documentation for the whole command tree as one markdown
+// document with a table of contents and a flag index.
+func GenSinglePageMarkdown(cmd *cobra.Command, w io.Writer) error {
+	tmpl, err := template.New("single-page").Funcs(docSiteFuncs()).Parse(singlePageMarkdownTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, NewDocSite(cmd))
+}
+
+const htmlLayoutTemplate = `{{ define "header" }}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ . }}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+pre { background: #f4f4f4; padding: 0.75em; overflow-x: auto; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 0.4em; text-align: left; vertical-align: top; }
+nav ul { list-style: none; padding-left: 1em; }
+</style>
+</head>
+<body>
+{{ end }}{{ define "footer" }}</body>
+</html>
+{{ end }}`
+
+const htmlIndexTemplate = `{{ template "header" .Root.Path }}<h1 id="top">{{ .Root.Path }}</h1>
+<p>{{ .Root.Short }}</p>
+<h2 id="contents">Commands</h2>
+<nav><ul>
+{{ range .Commands }}<li style="margin-left: {{ len .Parents }}em"><a href="{{ .Ref }}.html">{{ .Path }}</a> - {{ .Short }}</li>
+{{ end }}</ul></nav>
+<h2 id="flag-index">Flag Index</h2>
+<table>
+<tr><th>Flag</th><th>Accepted by</th><th>Config key</th><th>Environment variable</th></tr>
+{{ range .Flags }}{{ $flag := .Name }}<tr id="flag-{{ .Name }}"><td><code>--{{ .Name }}</code>{{ if .Shorthand }}, <code>-{{ .Shorthand }}</code>{{ end }}</td><td>{{ range $i, $c := .Commands }}{{ if $i }}, {{ end }}<a href="{{ $c.Ref }}.html#flag-{{ $flag }}">{{ $c.Path }}</a>{{ end }}</td><td>{{ .ConfigKey }}</td><td>{{ .EnvVar }}</td></tr>
+{{ end }}</table>
+{{ template "footer" }}`
+
+const htmlCommandTemplate = `{{ template "header" .Path }}<p><a href="index.html">index</a>{{ range .Parents }} / <a href="{{ .Ref }}.html">{{ .Name }}</a>{{ end }}</p>
+<h1 id="{{ .Ref }}">{{ .Path }}</h1>
+<p>{{ .Short }}</p>
+{{ if .Long }}<h2 id="synopsis">Synopsis</h2>
+<pre>{{ .Long }}</pre>
+{{ end }}{{ if .Runnable }}<h2 id="usage">Usage</h2>
+<pre>{{ .UseLine }}</pre>
+{{ end }}{{ if .Example }}<h2 id="examples">Examples</h2>
+<pre>{{ .Example }}</pre>
+{{ end }}{{ if .Flags }}<h2 id="options">Options</h2>
+{{ template "flags" .Flags }}{{ end }}{{ if .InheritedFlags }}<h2 id="inherited-options">Options inherited from parent commands</h2>
+{{ template "flags" .InheritedFlags }}{{ end }}{{ if .Children }}<h2 id="sub-commands">Sub commands</h2>
+<ul>
+{{ range .Children }}<li><a href="{{ .Ref }}.html">{{ .Path }}</a> - {{ .Short }}</li>
+{{ end }}</ul>
+{{ end }}{{ template "footer" }}
+{{ define "flags" }}<table>
+<tr><th>Flag</th><th>Type</th><th>Default</th><th>Description</th></tr>
+{{ range . }}<tr id="flag-{{ .Name }}"><td><code>--{{ .Name }}</code>{{ if .Shorthand }}, <code>-{{ .Shorthand }}</code>{{ end }}</td><td>{{ .Type }}</td><td>{{ .Default }}</td><td>{{ .Usage }}{{ if .ConfigKey }}<br>config: <code>{{ .ConfigKey }}</code>{{ end }}{{ if .EnvVar }}<br>env: <code>{{ .EnvVar }}</code>{{ end }}</td></tr>
+{{ end }}</table>
+{{ end }}`
+
+// GenHTMLSite writes a small static site for the command tree into dir: an index.html with the
+// command list and flag index plus one page per command named after the command's Ref.
+func GenHTMLSite(cmd *cobra.Command, dir string) error {
+	funcs := htmltemplate.FuncMap(docSiteFuncs())
+	layout, err := htmltemplate.New("layout").Funcs(funcs).Parse(htmlLayoutTemplate)
+	if err != nil {
+		return err
+	}
+	index, err := htmltemplate.Must(layout.Clone()).Parse(htmlIndexTemplate)
+	if err != nil {
+		return err
+	}
+	page, err := htmltemplate.Must(layout.Clone()).Parse(htmlCommandTemplate)
+	if err != nil {
+		return err
+	}
+
+	site := NewDocSite(cmd)
+	if err = renderHTMLFile(filepath.Join(dir, "index.html"), index, site); err != nil {
+		return err
+	}
+	for _, command := range site.Commands {
+		if err = renderHTMLFile(filepath.Join(dir, command.Ref+".html"), page, command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderHTMLFile(filename string, tmpl *htmltemplate.Template, data any) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = tmpl.Execute(f, data); err != nil {
+		return fmt.Errorf("unable to render [%s]: %w", filename, err)
+	}
+	return nil
+}
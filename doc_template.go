@@ -0,0 +1,206 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: doc_template.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// DefaultMarkdownTemplate renders the same sections as GenMarkdownCustom and is a starting point for custom templates.
+const DefaultMarkdownTemplate = `## {{ .Path }}
+
+{{ .Short }}
+{{ if .Long }}
+### Synopsis
+
+{{ .Long }}
+{{ end }}{{ if .Runnable }}
+` + "```" + `
+{{ .UseLine }}
+` + "```" + `
+{{ end }}{{ if .Example }}
+### Examples
+
+` + "```" + `
+{{ .Example }}
+` + "```" + `
+{{ end }}{{ if .Flags }}
+### Options
+
+{{ range .Flags }}* ` + "`" + `--{{ .Name }}{{ if .Shorthand }}, -{{ .Shorthand }}{{ end }}` + "`" +
+	` ({{ .Type }}{{ if .Default }}, default {{ quote .Default }}{{ end }}) - {{ .Usage }}
+{{ end }}{{ end }}{{ if .InheritedFlags }}
+### Options inherited from parent commands
+
+{{ range .InheritedFlags }}* ` + "`" + `--{{ .Name }}{{ if .Shorthand }}, -{{ .Shorthand }}{{ end }}` + "`" +
+	` ({{ .Type }}{{ if .Default }}, default {{ quote .Default }}{{ end }}) - {{ .Usage }}
+{{ end }}{{ end }}{{ if or .Parent .Children }}
+### SEE ALSO
+
+{{ with .Parent }}* [{{ .Path }}]({{ .Ref }}.md) - {{ .Short }}
+{{ end }}{{ range .Children }}* [{{ .Path }}]({{ .Ref }}.md) - {{ .Short }}
+{{ end }}{{ end }}`
+
+// FlagModel - A flag as seen by documentation templates
+type FlagModel struct {
+	Name      string
+	Shorthand string
+	Type      string
+	Default   string
+	Usage     string
+	Required  bool
+	ConfigKey string
+	EnvVar    string
+}
+
+// CommandRef - A reference to a related command. Ref is the command path joined with "_", the base
+// name of the command's generated file.
+type CommandRef struct {
+	Name  string
+	Path  string
+	Short string
+	Ref   string
+}
+
+// CommandModel - The documented view of a command handed to documentation templates
+type CommandModel struct {
+	CommandRef
+	UseLine        string
+	Long           string
+	Example        string
+	Aliases        []string
+	Runnable       bool
+	Deprecated     string
+	Flags          []FlagModel
+	InheritedFlags []FlagModel
+	Parent         *CommandRef
+	Parents        []CommandRef // From the root down to the direct parent
+	Children       []CommandRef
+}
+
+// NewCommandModel builds the documentation model for the command.
+func NewCommandModel(cmd *cobra.Command) *CommandModel {
+	cmd.InitDefaultHelpCmd()
+	cmd.InitDefaultHelpFlag()
+	inheritAutoGenTag(cmd)
+
+	model := &CommandModel{
+		CommandRef:     newCommandRef(cmd),
+		UseLine:        cmd.UseLine(),
+		Long:           cmd.Long,
+		Example:        cmd.Example,
+		Aliases:        cmd.Aliases,
+		Runnable:       cmd.Runnable(),
+		Deprecated:     cmd.Deprecated,
+		Flags:          newFlagModels(cmd.NonInheritedFlags()),
+		InheritedFlags: newFlagModels(cmd.InheritedFlags()),
+		Parents:        make([]CommandRef, 0),
+		Children:       make([]CommandRef, 0),
+	}
+
+	if cmd.HasParent() {
+		parent := newCommandRef(cmd.Parent())
+		model.Parent = &parent
+	}
+	cmd.VisitParents(
+		func(p *cobra.Command) {
+			model.Parents = append([]CommandRef{newCommandRef(p)}, model.Parents...)
+		},
+	)
+	for _, child := range availableChildren(cmd) {
+		model.Children = append(model.Children, newCommandRef(child))
+	}
+	return model
+}
+
+func newCommandRef(cmd *cobra.Command) CommandRef {
+	return CommandRef{
+		Name:  cmd.Name(),
+		Path:  cmd.CommandPath(),
+		Short: cmd.Short,
+		Ref:   strings.ReplaceAll(cmd.CommandPath(), " ", "_"),
+	}
+}
+
+// newFlagModels models the flags that help shows, leaving out hidden ones. Deprecated flags are
+// always hidden, so templates never see them.
+func newFlagModels(flags *pflag.FlagSet) []FlagModel {
+	models := make([]FlagModel, 0)
+	flags.VisitAll(
+		func(flag *pflag.Flag) {
+			if flag.Hidden {
+				return
+			}
+			required := flag.Annotations[cobra.BashCompOneRequiredFlag]
+			models = append(
+				models, FlagModel{
+					Name:      flag.Name,
+					Shorthand: flag.Shorthand,
+					Type:      flag.Value.Type(),
+					Default:   flag.DefValue,
+					Usage:     flag.Usage,
+					Required:  len(required) > 0 && required[0] == "true",
+					ConfigKey: flagAnnotation(flag, CONFIG_KEY_ANNOTATION),
+					EnvVar:    flagAnnotation(flag, ENV_VAR_ANNOTATION),
+				},
+			)
+		},
+	)
+	return models
+}
+
+// ParseDocTemplate parses a documentation template with the package's template funcs available.
+func ParseDocTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(TemplateFuncs()).Parse(text)
+}
+
+// GenTemplateCustom renders the command's CommandModel with the template.
+func GenTemplateCustom(cmd *cobra.Command, w io.Writer, tmpl *template.Template) error {
+	if err := tmpl.Execute(w, NewCommandModel(cmd)); err != nil {
+		return fmt.Errorf("unable to render docs for [%s]: %w", cmd.CommandPath(), err)
+	}
+	return nil
+}
+
+// GenTemplateTreeCustom renders one file per available command in the tree. Files are named
+// after the command's Ref plus the extension (e.g. ".md").
+func GenTemplateTreeCustom(cmd *cobra.Command, dir string, extension string, tmpl *template.Template) error {
+	return genDocTree(
+		cmd, dir, "_", extension, func(c *cobra.Command, w io.Writer, _ string) error {
+			return GenTemplateCustom(c, w, tmpl)
+		},
+	)
+}
@@ -0,0 +1,164 @@
+package golang_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDocTestRoot() *cobra.Command {
+	root := CommandBuilder("suzy").
+		SetShortDescription("suzy does things").
+		AddSubCommands(
+			CommandBuilder("q").
+				SetShortDescription("suzy q").
+				SetExample("suzy q --fast").
+				SetRun(func(*cobra.Command, []string) {}),
+		).
+		Build()
+	root.DisableAutoGenTag = true
+	return root
+}
+
+func TestGenReSTLinksParentAndChildren(t *testing.T) {
+	root := newDocTestRoot()
+	out := new(bytes.Buffer)
+	assert.NoError(t, GenReSTCustom(root, out, DefaultReSTLinkHandler))
+	assert.Contains(t, out.String(), ".. _suzy:")
+	assert.Contains(t, out.String(), "* :ref:`suzy q <suzy_q>` \t - suzy q")
+
+	out.Reset()
+	child, _, _ := root.Find([]string{"q"})
+	assert.NoError(t, GenReSTCustom(child, out, DefaultReSTLinkHandler))
+	assert.Contains(t, out.String(), "* :ref:`suzy <suzy>` \t - suzy does things")
+	assert.Contains(t, out.String(), "::\n\n  suzy q --fast")
+	assert.True(t, child.DisableAutoGenTag, "DisableAutoGenTag should be inherited from the root")
+}
+
+func TestGenJSONMatchesYamlContent(t *testing.T) {
+	out := new(bytes.Buffer)
+	child, _, _ := newDocTestRoot().Find([]string{"q"})
+	assert.NoError(t, GenJSONCustom(child, out))
+
+	var doc cmdDoc
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.Equal(t, "suzy q", doc.Name)
+	assert.Equal(t, "suzy q", doc.Synopsis)
+	assert.Equal(t, "suzy q --fast", doc.Example)
+	assert.Equal(t, []string{"suzy - suzy does things"}, doc.SeeAlso)
+}
+
+func TestGenTemplateCustomRendersCommandModel(t *testing.T) {
+	tmpl, err := ParseDocTemplate("test", `{{ split .Path }}`)
+	assert.Error(t, err, "unknown funcs should fail to parse")
+	assert.Nil(t, tmpl)
+
+	tmpl, err = ParseDocTemplate(
+		"test",
+		`{{ .Path }}|{{ range .Parents }}{{ .Ref }}{{ end }}|{{ orDefault "no long" .Long }}|`+
+			`{{ ternary .Runnable "runnable" "group" }}|{{ range .Flags }}{{ .Name }}:{{ .Type }}:{{ quote .Default }} {{ end }}`,
+	)
+	assert.NoError(t, err)
+
+	root := newDocTestRoot()
+	child, _, _ := root.Find([]string{"q"})
+	child.Flags().Int("count", 3, "how many")
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, GenTemplateCustom(child, out, tmpl))
+	assert.Equal(t, `suzy q|suzy|no long|runnable|count:int:"3" help:bool:"false" `, out.String())
+}
+
+func TestDefaultMarkdownTemplate(t *testing.T) {
+	tmpl, err := ParseDocTemplate("markdown", DefaultMarkdownTemplate)
+	assert.NoError(t, err)
+
+	child, _, _ := newDocTestRoot().Find([]string{"q"})
+	out := new(bytes.Buffer)
+	assert.NoError(t, GenTemplateCustom(child, out, tmpl))
+	assert.Contains(t, out.String(), "## suzy q\n")
+	assert.Contains(t, out.String(), "### Examples\n\n```\nsuzy q --fast\n```")
+	assert.Contains(t, out.String(), "* [suzy](suzy.md) - suzy does things")
+}
+
+func TestSinglePageMarkdownIndexesFlags(t *testing.T) {
+	root := CommandBuilder("suzy").
+		SetShortDescription("suzy does things").
+		AddPersistentFlags(
+			func(flags *pflag.FlagSet) {
+				flags.StringP("color", "c", "red", "the color | hue")
+			},
+		).
+		SetFlagConfigKey("color", "suzy.color").
+		SetFlagEnvVar("color", "SUZY_COLOR").
+		AddSubCommands(CommandBuilder("q").SetShortDescription("suzy q").SetRun(func(*cobra.Command, []string) {})).
+		Build()
+
+	site := NewDocSite(root)
+	assert.Len(t, site.Commands, 2)
+	assert.Len(t, site.Flags, 2, "color and help should be indexed")
+	color := site.Flags[0]
+	assert.Equal(t, "color", color.Name)
+	assert.Equal(t, "suzy.color", color.ConfigKey)
+	assert.Equal(t, "SUZY_COLOR", color.EnvVar)
+	assert.Equal(t, []string{"suzy", "suzy q"}, []string{color.Commands[0].Path, color.Commands[1].Path})
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, GenSinglePageMarkdown(root, out))
+	assert.Contains(t, out.String(), "  - [suzy q](#suzy_q)\n")
+	assert.Contains(t, out.String(), "<a id=\"suzy_q\"></a>")
+	assert.Contains(t, out.String(), "| `--color`, `-c` | [suzy](#suzy), [suzy q](#suzy_q) | suzy.color | SUZY_COLOR |")
+	assert.Contains(t, out.String(), "the color \\| hue")
+}
+
+func TestGenHTMLSiteLinksFlagsToCommandPages(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, GenHTMLSite(newDocTestRoot(), dir))
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(index), `<a href="suzy_q.html">suzy q</a>`)
+	assert.Contains(t, string(index), `<a href="suzy_q.html#flag-help">suzy q</a>`)
+
+	page, err := os.ReadFile(filepath.Join(dir, "suzy_q.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(page), `<tr id="flag-help">`)
+	assert.Contains(t, string(page), `<a href="suzy.html">suzy</a>`)
+}
+
+func TestVerifyMarkdownTreeReportsDrift(t *testing.T) {
+	dir := t.TempDir()
+	root := newDocTestRoot()
+	assert.NoError(t, GenMarkdownTreeCustom(root, dir, emptyFilePrepender, identityLinkHandler))
+	assert.NoError(t, VerifyMarkdownTree(root, dir))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "suzy_gone.md"), []byte("gone\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not ours\n"), 0644))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "suzy.md")))
+	child, _, _ := root.Find([]string{"q"})
+	child.Short = "suzy Q"
+
+	err := VerifyMarkdownTree(root, dir)
+	drift, ok := err.(*DocDrift)
+	assert.True(t, ok, "drift should be reported as a *DocDrift")
+	assert.Equal(t, []string{"suzy.md"}, drift.Added)
+	assert.Equal(t, []string{"suzy_gone.md"}, drift.Removed)
+	assert.Equal(t, []string{"suzy_q.md"}, drift.Changed)
+	assert.Contains(t, drift.Diffs["suzy_q.md"], "@@ -1,6 +1,6 @@\n ## suzy q\n \n-suzy q\n+suzy Q\n")
+}
+
+func TestUnifiedDiffSplitsDistantChangesIntoHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n"
+	assert.Equal(
+		t,
+		"--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		unifiedDiff("a", "b", a, b),
+	)
+}
@@ -0,0 +1,299 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: doc_verify.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const diffContextLines = 3
+
+// DocDrift - The differences between the generated docs for a command tree and the files on disk
+type DocDrift struct {
+	Dir     string
+	Added   []string          // Pages that would be generated but are not on disk
+	Removed []string          // Pages on disk for commands that no longer exist
+	Changed []string          // Pages whose content no longer matches
+	Diffs   map[string]string // Unified diffs, keyed by page, for added, removed and changed pages
+}
+
+func (d *DocDrift) HasDrift() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Changed) > 0
+}
+
+func (d *DocDrift) Error() string {
+	return fmt.Sprintf(
+		"documentation in [%s] is out of date: %d added, %d removed, %d changed",
+		d.Dir, len(d.Added), len(d.Removed), len(d.Changed),
+	)
+}
+
+// Report writes a summary of the drift followed by the diff for every page.
+func (d *DocDrift) Report(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString(d.Error() + "\n")
+	for _, section := range []struct {
+		title string
+		pages []string
+	}{{"Added", d.Added}, {"Removed", d.Removed}, {"Changed", d.Changed}} {
+		for _, page := range section.pages {
+			buf.WriteString(fmt.Sprintf("%s: %s\n", section.title, page))
+		}
+	}
+	pages := make([]string, 0, len(d.Diffs))
+	for page := range d.Diffs {
+		pages = append(pages, page)
+	}
+	sort.Strings(pages)
+	for _, page := range pages {
+		buf.WriteString("\n" + d.Diffs[page])
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// VerifyMarkdownTree checks the markdown in dir against what GenMarkdownTreeCustom would generate
+// for cmd with no file prepender and unchanged links. A *DocDrift error is returned when they differ.
+func VerifyMarkdownTree(cmd *cobra.Command, dir string) error {
+	return VerifyMarkdownTreeCustom(cmd, dir, emptyFilePrepender, identityLinkHandler)
+}
+
+// VerifyMarkdownTreeCustom is the same as VerifyMarkdownTree, but with custom filePrepender and linkHandler.
+func VerifyMarkdownTreeCustom(
+	cmd *cobra.Command,
+	dir string,
+	filePrepender func(string) string,
+	linkHandler func(string, string) string,
+) error {
+	generated := make(map[string]string)
+	err := walkDocTree(
+		cmd, dir, "_", ".md", func(c *cobra.Command, filename string) error {
+			buf := new(bytes.Buffer)
+			buf.WriteString(filePrepender(filename))
+			if err := GenMarkdownCustom(c, buf, filename, linkHandler); err != nil {
+				return err
+			}
+			generated[filepath.Base(filename)] = buf.String()
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	onDisk, err := readDocPages(dir, strings.ReplaceAll(cmd.CommandPath(), " ", "_"), ".md")
+	if err != nil {
+		return err
+	}
+
+	drift := diffDocPages(dir, generated, onDisk)
+	if drift.HasDrift() {
+		return drift
+	}
+	return nil
+}
+
+// readDocPages reads the files in dir that belong to the command tree rooted at the command whose
+// page is named prefix plus extension. Other files in the directory are ignored.
+func readDocPages(dir string, prefix string, extension string) (map[string]string, error) {
+	pages := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return pages, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != extension ||
+			(name != prefix+extension && !strings.HasPrefix(name, prefix+"_")) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		pages[name] = string(content)
+	}
+	return pages, nil
+}
+
+func diffDocPages(dir string, generated map[string]string, onDisk map[string]string) *DocDrift {
+	drift := &DocDrift{Dir: dir, Diffs: make(map[string]string)}
+	for page, want := range generated {
+		have, ok := onDisk[page]
+		switch {
+		case !ok:
+			drift.Added = append(drift.Added, page)
+			drift.Diffs[page] = unifiedDiff("/dev/null", page, "", want)
+		case have != want:
+			drift.Changed = append(drift.Changed, page)
+			drift.Diffs[page] = unifiedDiff(page, page, have, want)
+		}
+	}
+	for page, have := range onDisk {
+		if _, ok := generated[page]; !ok {
+			drift.Removed = append(drift.Removed, page)
+			drift.Diffs[page] = unifiedDiff(page, "/dev/null", have, "")
+		}
+	}
+	sort.Strings(drift.Added)
+	sort.Strings(drift.Removed)
+	sort.Strings(drift.Changed)
+	return drift
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// unifiedDiff returns the line diff from a to b in unified format with three lines of context.
+func unifiedDiff(fromName string, toName string, a string, b string) string {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+	for start := 0; start < len(ops); {
+		change := nextChange(ops, start)
+		if change < 0 {
+			break
+		}
+		hunkStart := max(change-diffContextLines, start)
+		hunkEnd := change
+		for next := change; next >= 0 && next <= hunkEnd+2*diffContextLines; next = nextChange(ops, hunkEnd+1) {
+			hunkEnd = next
+		}
+		hunkEnd = min(hunkEnd+diffContextLines+1, len(ops))
+		writeHunk(buf, ops, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+	return buf.String()
+}
+
+func nextChange(ops []diffOp, from int) int {
+	for i := from; i < len(ops); i++ {
+		if ops[i].kind != ' ' {
+			return i
+		}
+	}
+	return -1
+}
+
+func writeHunk(buf *bytes.Buffer, ops []diffOp, start int, end int) {
+	fromLine, toLine := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			fromLine++
+		}
+		if op.kind != '-' {
+			toLine++
+		}
+	}
+	fromLen, toLen := 0, 0
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			fromLen++
+		}
+		if op.kind != '-' {
+			toLen++
+		}
+	}
+	if fromLen == 0 {
+		fromLine--
+	}
+	if toLen == 0 {
+		toLine--
+	}
+
+	buf.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", fromLine, fromLen, toLine, toLen))
+	for _, op := range ops[start:end] {
+		buf.WriteByte(op.kind)
+		buf.WriteString(op.text + "\n")
+	}
+}
+
+// diffLines computes the edit script from a to b using the longest common subsequence of lines.
+func diffLines(a []string, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,157 @@
+/*
+ *
+ * Reproduced and modified from https://github.com/spf13/cobra
+ *
+ * Copyright 2013-2022 The Cobra Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, analyzer
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Filename: doc_yaml.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ *
+ */
+
+package golang_utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+type cmdOption struct {
+	Name         string `json:"name"`
+	Shorthand    string `yaml:",omitempty" json:"shorthand,omitempty"`
+	DefaultValue string `yaml:"default_value,omitempty" json:"default_value,omitempty"`
+	Usage        string `yaml:",omitempty" json:"usage,omitempty"`
+}
+
+type cmdDoc struct {
+	Name             string      `json:"name"`
+	Synopsis         string      `yaml:",omitempty" json:"synopsis,omitempty"`
+	Description      string      `yaml:",omitempty" json:"description,omitempty"`
+	Usage            string      `yaml:",omitempty" json:"usage,omitempty"`
+	Options          []cmdOption `yaml:",omitempty" json:"options,omitempty"`
+	InheritedOptions []cmdOption `yaml:"inherited_options,omitempty" json:"inherited_options,omitempty"`
+	Example          string      `yaml:",omitempty" json:"example,omitempty"`
+	SeeAlso          []string    `yaml:"see_also,omitempty" json:"see_also,omitempty"`
+}
+
+// GenYamlCustom creates a yaml command reference for the command.
+func GenYamlCustom(cmd *cobra.Command, w io.Writer) error {
+	final, err := yaml.Marshal(newCmdDoc(cmd, forceMultiLine))
+	if err != nil {
+		return fmt.Errorf("unable to marshal yaml for [%s]: %w", cmd.CommandPath(), err)
+	}
+	_, err = w.Write(final)
+	return err
+}
+
+// GenJSONCustom creates a json command reference for the command with the same content as GenYamlCustom.
+func GenJSONCustom(cmd *cobra.Command, w io.Writer) error {
+	final, err := json.MarshalIndent(newCmdDoc(cmd, strings.TrimSpace), "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal json for [%s]: %w", cmd.CommandPath(), err)
+	}
+	_, err = w.Write(append(final, '\n'))
+	return err
+}
+
+// newCmdDoc collects the reference content for the command. Long text is passed through format
+// to suit the output encoding.
+func newCmdDoc(cmd *cobra.Command, format func(string) string) *cmdDoc {
+	cmd.InitDefaultHelpCmd()
+	cmd.InitDefaultHelpFlag()
+	inheritAutoGenTag(cmd)
+
+	doc := &cmdDoc{
+		Name:        cmd.CommandPath(),
+		Synopsis:    format(cmd.Short),
+		Description: format(cmd.Long),
+	}
+
+	if cmd.Runnable() {
+		doc.Usage = cmd.UseLine()
+	}
+	if len(cmd.Example) > 0 {
+		doc.Example = cmd.Example
+	}
+
+	doc.Options = genFlagResult(cmd.NonInheritedFlags(), format)
+	doc.InheritedOptions = genFlagResult(cmd.InheritedFlags(), format)
+
+	if hasSeeAlso(cmd) {
+		if cmd.HasParent() {
+			parent := cmd.Parent()
+			doc.SeeAlso = append(doc.SeeAlso, parent.CommandPath()+" - "+parent.Short)
+		}
+		for _, child := range availableChildren(cmd) {
+			doc.SeeAlso = append(doc.SeeAlso, cmd.CommandPath()+" "+child.Name()+" - "+child.Short)
+		}
+	}
+	return doc
+}
+
+// GenYamlTreeCustom is the yaml counterpart of GenMarkdownTreeCustom.
+func GenYamlTreeCustom(cmd *cobra.Command, dir string, filePrepender func(string) string) error {
+	return genDocTree(
+		cmd, dir, "_", ".yaml", func(c *cobra.Command, w io.Writer, filename string) error {
+			if _, err := io.WriteString(w, filePrepender(filename)); err != nil {
+				return err
+			}
+			return GenYamlCustom(c, w)
+		},
+	)
+}
+
+// GenJSONTreeCustom is the json counterpart of GenMarkdownTreeCustom.
+func GenJSONTreeCustom(cmd *cobra.Command, dir string, filePrepender func(string) string) error {
+	return genDocTree(
+		cmd, dir, "_", ".json", func(c *cobra.Command, w io.Writer, filename string) error {
+			if _, err := io.WriteString(w, filePrepender(filename)); err != nil {
+				return err
+			}
+			return GenJSONCustom(c, w)
+		},
+	)
+}
+
+func genFlagResult(flags *pflag.FlagSet, format func(string) string) []cmdOption {
+	var result []cmdOption
+
+	flags.VisitAll(
+		func(flag *pflag.Flag) {
+			if flag.Hidden || len(flag.Deprecated) > 0 {
+				return
+			}
+			opt := cmdOption{
+				Name:         flag.Name,
+				DefaultValue: flag.DefValue,
+				Usage:        format(strings.TrimSpace(flag.Usage)),
+			}
+			if len(flag.ShorthandDeprecated) == 0 && len(flag.Shorthand) > 0 {
+				opt.Shorthand = flag.Shorthand
+			}
+			result = append(result, opt)
+		},
+	)
+
+	return result
+}
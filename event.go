@@ -5,6 +5,7 @@ import (
 	"golang.org/x/sync/errgroup"
 	"reflect"
 	"strings"
+	"time"
 )
 
 var (
@@ -158,6 +159,7 @@ func (b *Bus) Send(event Event) {
 				missedHandlers := make([]Handler, 0, len(registration.handlers))
 				missedHandlers = append(missedHandlers, registration.handlers...)
 				for i, handler := range registration.handlers {
+					i, handler := i, handler
 					//Send Event to each registered consumer in separate goroutine!
 					eg.Go(
 						func() error {
@@ -276,3 +278,74 @@ func SendErrorEvent(source string, err error, msg string, args ...interface{}) {
 	EventBus.Send(NewErrorEvent(source, err, msg, args...))
 	SendAppLogEvent(msg, args...)
 }
+
+const (
+	CMD_STARTED_EVENT  = "cmd.started"
+	CMD_FINISHED_EVENT = "cmd.finished"
+	CMD_FAILED_EVENT   = "cmd.failed"
+)
+
+type CommandEvent struct {
+	DefaultEvent
+}
+
+func NewEmptyCommandEvent() *CommandEvent {
+	return &CommandEvent{}
+}
+
+func NewCommandEvent(name, cmd string, args []string, duration time.Duration, err error) *CommandEvent {
+	msg := fmt.Sprintf("%s %s", cmd, strings.TrimPrefix(name, "cmd."))
+	if err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, err)
+	}
+	return &CommandEvent{
+		DefaultEvent{
+			TypeName: name,
+			Msg:      msg,
+			Dmn:      cmd,
+			Err:      err,
+			DataMap:  map[string]any{"command": cmd, "args": args, "duration": duration},
+		},
+	}
+}
+
+const (
+	CONFIG_CHANGED_EVENT       = "config.changed"
+	CONFIG_RELOAD_FAILED_EVENT = "config.reload-failed"
+)
+
+type ConfigChangedEvent struct {
+	DefaultEvent
+	Path    string
+	Changed []string
+}
+
+func NewEmptyConfigChangedEvent() *ConfigChangedEvent {
+	return &ConfigChangedEvent{}
+}
+
+func NewConfigChangedEvent(path string, changed []string) *ConfigChangedEvent {
+	return &ConfigChangedEvent{
+		DefaultEvent: DefaultEvent{
+			TypeName: CONFIG_CHANGED_EVENT,
+			Msg:      fmt.Sprintf("config [%s] changed: %s", path, strings.Join(changed, ", ")),
+			Dmn:      path,
+			DataMap:  map[string]any{"path": path, "changed": changed},
+		},
+		Path:    path,
+		Changed: changed,
+	}
+}
+
+func NewConfigReloadFailedEvent(path string, err error) *ConfigChangedEvent {
+	return &ConfigChangedEvent{
+		DefaultEvent: DefaultEvent{
+			TypeName: CONFIG_RELOAD_FAILED_EVENT,
+			Msg:      fmt.Sprintf("config [%s] was not reloaded, keeping the last good config: %v", path, err),
+			Dmn:      path,
+			Err:      err,
+			DataMap:  map[string]any{"path": path},
+		},
+		Path: path,
+	}
+}
@@ -310,7 +310,7 @@ func CreateUniqueTempDir(parentDir string) *FileInfo {
 
 func CreateTempFile(dir string, filename string) *FileInfo {
 	file, err := os.CreateTemp(dir, filename)
-	CheckError(err, "Unable to create temporary file [%s] @ path [@s]", filename, dir)
+	CheckError(err, "Unable to create temporary file [%s] @ path [%s]", filename, dir)
 
 	return &FileInfo{
 		Name:        path.Base(file.Name()),
@@ -0,0 +1,308 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: harness.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/spf13/cobra"
+)
+
+// UPDATE_GOLDEN_ENV - When this environment variable is set golden files are (re)written instead of compared
+const UPDATE_GOLDEN_ENV = "UPDATE_GOLDEN"
+
+// TestingT - The subset of testing.TB used by the harness assertions
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// CmdHarness - Executes command trees built by a CmdConfig in isolation for tests.
+// Every run gets a fresh State, viper and EventBus, its own temp home and work dir, and captured
+// stdout/stderr. Runs change process wide state (env, working dir, os.Stdout) so they must not be
+// used from parallel tests.
+type CmdHarness struct {
+	build func() *cobra.Command
+	env   map[string]string
+	stdin string
+}
+
+// CmdResult - The outcome of a harness run
+type CmdResult struct {
+	Args     []string
+	Stdout   string
+	Stderr   string
+	Events   []Event
+	ExitCode int
+	Err      error
+	HomeDir  string
+	WorkDir  string
+}
+
+// NewCmdHarness creates a harness that builds a new command tree for every run.
+func NewCmdHarness(build func() *cobra.Command) *CmdHarness {
+	return &CmdHarness{
+		build: build,
+		env:   make(map[string]string),
+	}
+}
+
+func (h *CmdHarness) WithEnv(key string, value string) *CmdHarness {
+	h.env[key] = value
+	return h
+}
+
+// WithStdin sets the input available to the command, including answers to prompts.
+func (h *CmdHarness) WithStdin(input string) *CmdHarness {
+	h.stdin = input
+	return h
+}
+
+// Run executes the command tree with the given args.
+func (h *CmdHarness) Run(args ...string) *CmdResult {
+	result := &CmdResult{Args: args}
+
+	restoreDirs, err := h.isolateDirs(result)
+	if err != nil {
+		result.Err = err
+		result.ExitCode = 1
+		return result
+	}
+	defer restoreDirs()
+
+	restoreEnv := h.applyEnv(result)
+	defer restoreEnv()
+
+	ResetGlobals()
+	defer ResetGlobals()
+
+	stdout, err := captureOutput(&os.Stdout)
+	if err != nil {
+		result.Err = err
+		result.ExitCode = 1
+		return result
+	}
+	stderr, err := captureOutput(&os.Stderr)
+	if err != nil {
+		stdout()
+		result.Err = err
+		result.ExitCode = 1
+		return result
+	}
+
+	state := CurrentState()
+	state.homeDir = result.HomeDir
+	state.workDir = result.WorkDir
+
+	var eventLock sync.Mutex
+	EventBus.RegisterHandler(
+		NewRegistration(
+			"*", nil, func(event Event) error {
+				eventLock.Lock()
+				defer eventLock.Unlock()
+				result.Events = append(result.Events, event)
+				return nil
+			},
+		),
+	)
+	SetPrompter(NewPrompter(strings.NewReader(h.stdin), os.Stdout))
+
+	result.Err = h.execute(args)
+
+	result.Stderr = stderr()
+	result.Stdout = stdout()
+	log.SetHandler(cli.New(os.Stdout))
+
+	if result.Err != nil || state.Errored() {
+		result.ExitCode = 1
+	}
+	return result
+}
+
+func (h *CmdHarness) execute(args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("command panicked: %v", r)
+			CurrentState().Shutdown()
+		}
+	}()
+
+	root := h.build()
+	root.SetArgs(args)
+	root.SetIn(strings.NewReader(h.stdin))
+	return Execute(root)
+}
+
+func (h *CmdHarness) isolateDirs(result *CmdResult) (restore func(), err error) {
+	if result.HomeDir, err = os.MkdirTemp("", "harness-home-"); err != nil {
+		return nil, err
+	}
+	if result.WorkDir, err = os.MkdirTemp("", "harness-work-"); err != nil {
+		RemoveDir(result.HomeDir)
+		return nil, err
+	}
+
+	previous, err := os.Getwd()
+	if err == nil {
+		err = os.Chdir(result.WorkDir)
+	}
+	if err != nil {
+		RemoveDir(result.HomeDir)
+		RemoveDir(result.WorkDir)
+		return nil, err
+	}
+
+	return func() {
+		LogError(os.Chdir(previous), "Unable to restore working dir [%s]", previous)
+		RemoveDir(result.HomeDir)
+		RemoveDir(result.WorkDir)
+	}, nil
+}
+
+func (h *CmdHarness) applyEnv(result *CmdResult) (restore func()) {
+	env := map[string]string{"HOME": result.HomeDir}
+	AddAll(env, h.env)
+
+	previous := make(map[string]*string)
+	for k, v := range env {
+		if old, ok := os.LookupEnv(k); ok {
+			previous[k] = &old
+		} else {
+			previous[k] = nil
+		}
+		LogError(os.Setenv(k, v), "Unable to set env [%s]", k)
+	}
+
+	return func() {
+		for k, v := range previous {
+			if v == nil {
+				LogError(os.Unsetenv(k), "Unable to unset env [%s]", k)
+			} else {
+				LogError(os.Setenv(k, *v), "Unable to restore env [%s]", k)
+			}
+		}
+	}
+}
+
+// captureOutput redirects the target file (os.Stdout or os.Stderr) into a pipe. The returned
+// function restores the target and returns everything written to it.
+func captureOutput(target **os.File) (func() string, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	original := *target
+	*target = writer
+
+	captured := new(bytes.Buffer)
+	copied := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(captured, reader)
+		close(copied)
+	}()
+
+	return func() string {
+		*target = original
+		_ = writer.Close()
+		<-copied
+		_ = reader.Close()
+		return captured.String()
+	}, nil
+}
+
+// ResetGlobals discards the current State, along with its configuration, EventBus registrations and prompter.
+func ResetGlobals() {
+	lock.Lock()
+	appState = nil
+	lock.Unlock()
+	Reset()
+	SetPrompter(nil)
+}
+
+// EventNames returns the names of the captured events in the order they were sent.
+func (r *CmdResult) EventNames() []string {
+	names := make([]string, 0, len(r.Events))
+	for _, event := range r.Events {
+		names = append(names, event.Name())
+	}
+	return names
+}
+
+func (r *CmdResult) AssertStdoutGolden(t TestingT, goldenFile string) {
+	t.Helper()
+	AssertGolden(t, goldenFile, r.Stdout)
+}
+
+func (r *CmdResult) AssertStderrGolden(t TestingT, goldenFile string) {
+	t.Helper()
+	AssertGolden(t, goldenFile, r.Stderr)
+}
+
+// AssertGolden compares actual with the contents of goldenFile. With UPDATE_GOLDEN set the
+// golden file is written instead.
+func AssertGolden(t TestingT, goldenFile string, actual string) {
+	t.Helper()
+
+	if IsNotEmpty(os.Getenv(UPDATE_GOLDEN_ENV)) {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0755); err != nil {
+			t.Fatalf("unable to create dir for golden file [%s]: %v", goldenFile, err)
+		}
+		if err := os.WriteFile(goldenFile, []byte(actual), 0644); err != nil {
+			t.Fatalf("unable to write golden file [%s]: %v", goldenFile, err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(goldenFile)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("golden file [%s] does not exist, run with %s=1 to create it", goldenFile, UPDATE_GOLDEN_ENV)
+		return
+	}
+	if err != nil {
+		t.Fatalf("unable to read golden file [%s]: %v", goldenFile, err)
+		return
+	}
+	if string(expected) != actual {
+		t.Errorf(
+			"output does not match golden file [%s] (run with %s=1 to update)\n--- expected\n%s\n--- actual\n%s",
+			goldenFile, UPDATE_GOLDEN_ENV, string(expected), actual,
+		)
+	}
+}
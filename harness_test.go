@@ -0,0 +1,70 @@
+package golang_utils
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+// withFreshState gives the test a new State, putting the previous one back once it is done. Unlike
+// ResetGlobals the event bus and prompter are left alone.
+func withFreshState(t *testing.T) *State {
+	lock.Lock()
+	previous := appState
+	appState = nil
+	lock.Unlock()
+	t.Cleanup(
+		func() {
+			lock.Lock()
+			appState = previous
+			lock.Unlock()
+		},
+	)
+	return CurrentState()
+}
+
+func newHarnessTestRoot() *cobra.Command {
+	CurrentState().SetAppName("suzy").SetVersion("1.2.3").SetCommitSha("abc123").SetBuildDate("2026-10-18")
+	return AttachStandardCommands(
+		CommandBuilder("suzy").
+			AddSubCommands(
+				CommandBuilder("where").
+					Use(EventMiddleware()).
+					SetRun(
+						func(cmd *cobra.Command, _ []string) {
+							fmt.Fprintln(cmd.OutOrStdout(), CurrentState().HomeDir() == os.Getenv("HOME"), os.Getenv("SUZY_COLOR"))
+						},
+					),
+				CommandBuilder("fail").
+					SetRun(func(*cobra.Command, []string) { ThrowError("suzy failed") }),
+			).
+			Build(),
+	)
+}
+
+func TestHarnessCapturesOutputAndEvents(t *testing.T) {
+	result := NewCmdHarness(newHarnessTestRoot).WithEnv("SUZY_COLOR", "red").Run("where")
+
+	assert.NoError(t, result.Err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "true red\n", result.Stdout, "the run should use an isolated home dir and the given env")
+	assert.Equal(t, []string{CMD_STARTED_EVENT, CMD_FINISHED_EVENT}, result.EventNames())
+	assert.False(t, PathExists(result.HomeDir), "the temp home dir should be removed after the run")
+	_, set := os.LookupEnv("SUZY_COLOR")
+	assert.False(t, set, "the env should be restored after the run")
+}
+
+func TestHarnessReportsFailures(t *testing.T) {
+	result := NewCmdHarness(newHarnessTestRoot).Run("fail")
+
+	assert.Error(t, result.Err)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, result.EventNames(), "error")
+}
+
+func TestHarnessGoldenOutput(t *testing.T) {
+	NewCmdHarness(newHarnessTestRoot).Run("version").AssertStdoutGolden(t, "testdata/version.golden")
+}
@@ -40,24 +40,35 @@ func GetPointer[V any](value V) *V {
 	return &value
 }
 
+// GetConfig returns the app's config property converted to T, see GetAs.
+func GetConfig[T any](key string) (T, error) {
+	return GetAs[T](CurrentState().Config(), key)
+}
+
+// GetConfigOr returns the app's config property converted to T, or defaultValue when it is unset.
+// See GetOr.
+func GetConfigOr[T any](key string, defaultValue T) T {
+	return GetOr(CurrentState().Config(), key, defaultValue)
+}
+
 func GetConfigString(key string) string {
-	return CurrentState().config.Get(key)
+	return CurrentState().Config().Get(key)
 }
 
 func GetConfigBool(key string) bool {
-	return CurrentState().config.GetBool(key)
+	return CurrentState().Config().GetBool(key)
 }
 
 func GetConfigIntWithDefault(key string, defaultValue int) int {
-	return CurrentState().config.GetIntWithDefault(key, defaultValue)
+	return GetConfigOr(key, defaultValue)
 }
 
 func GetConfigList(key string) []string {
-	return CurrentState().config.GetList(key)
+	return CurrentState().Config().GetList(key)
 }
 
 func GetConfigFloat(key string) float64 {
-	return CurrentState().config.GetFloat(key)
+	return CurrentState().Config().GetFloat(key)
 }
 
 func RandomWaitMillis(max int) {
@@ -0,0 +1,244 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: history.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const REDACTED = "******"
+
+// SecretKeyRegex - Flag, argument and property names matching this are treated as secrets and redacted
+var SecretKeyRegex = NewRegex(`(?i)(pass(word|wd)?|secret|token|api[-_.]?key|credential|private[-_.]?key)`)
+
+// CommandRun - A single recorded invocation of a command
+type CommandRun struct {
+	ID         uint      `gorm:"primarykey"`
+	Command    string    `gorm:"index"`
+	Args       string    // json encoded list of the (redacted) flags and args
+	StartTime  time.Time `gorm:"index"`
+	StopTime   time.Time
+	Duration   time.Duration
+	ExitStatus int
+	Error      string
+}
+
+// HistoryQuery - Filters applied when reading the run history. Zero values are ignored.
+type HistoryQuery struct {
+	Command    string // command name e.g. "app.config", its sub commands match too
+	Since      time.Time
+	FailedOnly bool
+	Limit      int
+}
+
+func (r *CommandRun) Arguments() []string {
+	args := make([]string, 0)
+	if IsNotEmpty(r.Args) {
+		LogError(json.Unmarshal([]byte(r.Args), &args), "Unable to decode args for run [%d]", r.ID)
+	}
+	return args
+}
+
+func (r *CommandRun) Failed() bool {
+	return r.ExitStatus != 0
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, using \ as the escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func IsSecretKey(name string) bool {
+	return SecretKeyRegex.Matches(name)
+}
+
+// RedactArgs masks the values of secret flags (--token=abc, --token abc) and secret key=value args.
+// Given the command's flags, a secret flag only masks the arg after it when it takes a value, so
+// --token-only arg leaves arg alone. Without them the arg after a secret flag is always masked.
+func RedactArgs(args []string, flags ...*pflag.FlagSet) []string {
+	redacted := make([]string, len(args))
+	maskNext := false
+	for i, arg := range args {
+		switch {
+		case maskNext && !strings.HasPrefix(arg, "-"):
+			redacted[i] = REDACTED
+			maskNext = false
+		case strings.Contains(arg, "="):
+			name, _, _ := strings.Cut(arg, "=")
+			if IsSecretKey(name) {
+				arg = name + "=" + REDACTED
+			}
+			redacted[i] = arg
+			maskNext = false
+		default:
+			redacted[i] = arg
+			maskNext = strings.HasPrefix(arg, "-") && IsSecretKey(arg) && takesValue(arg, flags)
+		}
+	}
+	return redacted
+}
+
+// takesValue reports whether the flag arg takes a value, assuming it does when it isn't in flags.
+func takesValue(arg string, flags []*pflag.FlagSet) bool {
+	name := strings.TrimLeft(arg, "-")
+	for _, set := range flags {
+		flag := set.Lookup(name)
+		if flag == nil && len(name) == 1 {
+			flag = set.ShorthandLookup(name)
+		}
+		if flag != nil {
+			return flag.NoOptDefVal == ""
+		}
+	}
+	return true
+}
+
+func cmdArgs(cmd *cobra.Command, args []string) []string {
+	all := make([]string, 0, len(args))
+	cmd.Flags().Visit(
+		func(flag *pflag.Flag) {
+			all = append(all, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+		},
+	)
+	return RedactArgs(append(all, args...), cmd.Flags())
+}
+
+// EnableRunHistory records every tracked command invocation through the PersistenceContext.
+func (s *State) EnableRunHistory() *State {
+	s.runHistory = true
+	s.migrateRunHistory()
+	return s
+}
+
+func (s *State) migrateRunHistory() {
+	if s.runHistory && s.PersistenceContext != nil && s.PersistenceContext.DB != nil {
+		CheckError(s.PersistenceContext.DB.AutoMigrate(&CommandRun{}), "error initializing run history schema")
+	}
+}
+
+func (s *State) recordsHistory() bool {
+	return s.runHistory && s.PersistenceContext != nil && s.PersistenceContext.DB != nil
+}
+
+// StartRun records the start of a command invocation and assigns its RunID.
+func (s *State) StartRun(cmd *cobra.Command, args []string) {
+	if !s.recordsHistory() {
+		return
+	}
+
+	encoded, err := json.Marshal(cmdArgs(cmd, args))
+	LogError(err, "Unable to encode args for cmd [%s]", GetFullCmdName(cmd))
+
+	run := &CommandRun{
+		Command:   GetFullCmdName(cmd),
+		Args:      string(encoded),
+		StartTime: time.Now(),
+	}
+	if err := s.runs().Create(run); err != nil {
+		LogError(err, "Unable to record start of cmd [%s]", run.Command)
+		return
+	}
+
+	s.Lock()
+	s.currentRun = run
+	if s.config != nil {
+		s.config.RunID = run.ID
+	}
+	s.Unlock()
+	log.Debugf("Started run [%d] of cmd [%s]", run.ID, run.Command)
+}
+
+// StopRun records the outcome of the current command invocation. A non nil err, or the State
+// having errored during the run, marks the run as failed.
+func (s *State) StopRun(err error) {
+	s.Lock()
+	run := s.currentRun
+	s.currentRun = nil
+	s.Unlock()
+
+	if run == nil || !s.recordsHistory() {
+		return
+	}
+
+	if err == nil && s.State() == Errored {
+		err = s.LastError()
+		if err == nil {
+			err = fmt.Errorf("application errored")
+		}
+	}
+
+	run.StopTime = time.Now()
+	run.Duration = run.StopTime.Sub(run.StartTime)
+	if err != nil {
+		run.ExitStatus = 1
+		run.Error = err.Error()
+	}
+
+	LogError(s.runs().Save(run), "Unable to record end of run [%d]", run.ID)
+	log.Debugf("Stopped run [%d] of cmd [%s] after %s", run.ID, run.Command, run.Duration)
+}
+
+// RunID returns the id of the command invocation currently being recorded, 0 if there is none.
+func (s *State) RunID() uint {
+	s.Lock()
+	defer s.Unlock()
+	if s.currentRun == nil {
+		return 0
+	}
+	return s.currentRun.ID
+}
+
+// History returns the recorded command invocations matching the query, newest first.
+func (s *State) History(filter HistoryQuery) ([]CommandRun, error) {
+	if !s.recordsHistory() {
+		return nil, fmt.Errorf("run history is not enabled")
+	}
+
+	query := NewQuery()
+	if IsNotEmpty(filter.Command) {
+		query.Where("(command = ? OR command LIKE ? ESCAPE '\\')", filter.Command, likeEscaper.Replace(filter.Command)+".%")
+	}
+	if !filter.Since.IsZero() {
+		query.Where("start_time >= ?", filter.Since)
+	}
+	if filter.FailedOnly {
+		query.Where("exit_status <> 0")
+	}
+	return s.runs().Find(query.OrderBy("start_time desc").OrderBy("id desc").Limit(filter.Limit))
+}
+
+func (s *State) runs() *Repository[CommandRun] {
+	return NewRepository[CommandRun](s.PersistenceContext)
+}
@@ -0,0 +1,90 @@
+package golang_utils
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactArgs(t *testing.T) {
+	redacted := RedactArgs([]string{"--token=abc", "--user=suzy", "--password", "hunter2", "api_key=xyz", "plain"})
+	assert.Equal(
+		t,
+		[]string{"--token=" + REDACTED, "--user=suzy", "--password", REDACTED, "api_key=" + REDACTED, "plain"},
+		redacted,
+	)
+
+	flags := pflag.NewFlagSet("suzy", pflag.ContinueOnError)
+	flags.Bool("token-only", false, "only print the token")
+	flags.String("token", "", "the token")
+	assert.Equal(
+		t, []string{"--token-only", "plain", "--token", REDACTED},
+		RedactArgs([]string{"--token-only", "plain", "--token", "abc"}, flags),
+		"only secret flags that take a value should mask the next arg",
+	)
+}
+
+func TestRunHistoryRecordsInvocations(t *testing.T) {
+	state := CurrentState()
+	state.EnablePersistence(NewPersistenceConfig("history.db", t.TempDir(), []any{})).EnableRunHistory()
+	defer func() {
+		state.runHistory = false
+		state.PersistenceContext = nil
+	}()
+
+	var runID, preRunID uint
+	root := CommandBuilder("suzy").
+		AddSubCommands(
+			CommandBuilder("q").
+				AddFlags(
+					func(flags *pflag.FlagSet) {
+						flags.String("secret", "", "a secret")
+					},
+				).
+				SetPreRun(func(*cobra.Command, []string) { preRunID = CurrentState().RunID() }).
+				SetRun(func(*cobra.Command, []string) { runID = CurrentState().RunID() }),
+		).
+		Build()
+	root.SetArgs([]string{"q", "--secret", "shh", "arg1"})
+	assert.NoError(t, root.Execute())
+
+	runs, err := state.History(HistoryQuery{Command: "suzy.q"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(runs))
+	assert.Equal(t, runID, runs[0].ID, "The RunID should be assigned when the run starts")
+	assert.Equal(t, runID, preRunID, "The pre run should already see the RunID")
+	assert.Equal(t, "suzy.q", runs[0].Command)
+	assert.Equal(t, []string{"--secret=" + REDACTED, "arg1"}, runs[0].Arguments())
+	assert.False(t, runs[0].Failed())
+	assert.False(t, runs[0].StopTime.IsZero())
+	assert.Equal(t, uint(0), state.RunID(), "No run should be active once the command finished")
+}
+
+func TestHistoryMatchesTheCommandAndItsSubCommands(t *testing.T) {
+	state := CurrentState()
+	state.EnablePersistence(NewPersistenceConfig("history.db", t.TempDir(), []any{})).EnableRunHistory()
+	defer func() {
+		state.runHistory = false
+		state.PersistenceContext = nil
+	}()
+	for _, command := range []string{"suzy.q", "suzy.q.sub", "suzy.qq", "suzy_q", "suzyxq"} {
+		assert.NoError(t, state.runs().Create(&CommandRun{Command: command, StartTime: time.Now()}))
+	}
+
+	commands := func(filter string) []string {
+		runs, err := state.History(HistoryQuery{Command: filter})
+		assert.NoError(t, err)
+		names := make([]string, 0, len(runs))
+		for _, run := range runs {
+			names = append(names, run.Command)
+		}
+		sort.Strings(names)
+		return names
+	}
+	assert.Equal(t, []string{"suzy.q", "suzy.q.sub"}, commands("suzy.q"))
+	assert.Equal(t, []string{"suzy_q"}, commands("suzy_q"), "wildcards in the command should be matched literally")
+}
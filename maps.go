@@ -32,6 +32,8 @@ package golang_utils
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"reflect"
 	"strings"
 )
@@ -119,16 +121,26 @@ func Print[K comparable, V any](target map[K]V, msg string, args ...any) {
 	PrintPadded(target, 0, msg, args...)
 }
 
+// Fprint is Print writing to w, e.g. a command's cmd.OutOrStdout().
+func Fprint[K comparable, V any](w io.Writer, target map[K]V, msg string, args ...any) {
+	FprintPadded(w, target, 0, msg, args...)
+}
+
 func PrintPadded[K comparable, V any](target map[K]V, depth int, msg string, args ...any) {
+	FprintPadded(os.Stdout, target, depth, msg, args...)
+}
+
+func FprintPadded[K comparable, V any](w io.Writer, target map[K]V, depth int, msg string, args ...any) {
 	var builder strings.Builder
 	padd := strings.Repeat(" ", depth)
 	for k, v := range target {
 		builder.WriteString(fmt.Sprintf("%s%v: %s", padd, k, getValue(v, depth+1)))
 	}
 
-	fmt.Printf(fmt.Sprintf("%s%s", padd, msg), args...)
-	fmt.Printf("\n%s\n", builder.String())
+	fmt.Fprintf(w, fmt.Sprintf("%s%s", padd, msg), args...)
+	fmt.Fprintf(w, "\n%s\n", builder.String())
 }
+
 func getValue(value any, depth int) string {
 	actualValue := reflect.ValueOf(value)
 	if actualValue.Kind() == reflect.Map {
@@ -0,0 +1,194 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: middleware.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+const VERBOSE_FLAG_NAME = "verbose"
+
+// Middleware - Wraps a command's PreRun, Run and PostRun functions. Middleware registered first runs outermost.
+type Middleware func(next CmdFunc) CmdFunc
+
+// Use adds middleware to the chain applied around the command's PreRun, Run and PostRun functions.
+func (cc *CmdConfig) Use(middleware ...Middleware) *CmdConfig {
+	for _, m := range middleware {
+		if m != nil {
+			cc.middleware = append(cc.middleware, m)
+		}
+	}
+	return cc
+}
+
+// StandardMiddleware returns the state, verbose flag, profile flag, timing and event middleware in
+// that order.
+func StandardMiddleware() []Middleware {
+	return []Middleware{
+		StateMiddleware(),
+		VerboseMiddleware(VERBOSE_FLAG_NAME),
+		ProfileMiddleware(PROFILE_FLAG_NAME),
+		TimingMiddleware(),
+		EventMiddleware(),
+	}
+}
+
+func chainMiddleware(run CmdFunc, middleware []Middleware) CmdFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		run = middleware[i](run)
+	}
+	return run
+}
+
+// runGuarded runs next and returns the value of any panic it raised, or nil.
+func runGuarded(next CmdFunc, cmd *cobra.Command, args []string) (panicked any) {
+	defer func() {
+		panicked = recover()
+	}()
+	next(cmd, args)
+	return nil
+}
+
+func panicError(panicked any) error {
+	if panicked == nil {
+		return nil
+	}
+	if err, ok := panicked.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", panicked)
+}
+
+// TrackingMiddleware tracks the command on the State and records the run in the run history.
+func TrackingMiddleware() Middleware {
+	return func(next CmdFunc) CmdFunc {
+		return func(cmd *cobra.Command, args []string) {
+			state := CurrentState()
+			state.TrackCmd(cmd, args)
+			panicked := runGuarded(next, cmd, args)
+			state.StopRun(panicError(panicked))
+			if panicked != nil {
+				panic(panicked)
+			}
+		}
+	}
+}
+
+// TimingMiddleware logs how long the command took to run.
+func TimingMiddleware() Middleware {
+	return func(next CmdFunc) CmdFunc {
+		return func(cmd *cobra.Command, args []string) {
+			start := time.Now()
+			panicked := runGuarded(next, cmd, args)
+			log.Debugf("cmd [%s] completed in %s", GetFullCmdName(cmd), time.Since(start))
+			if panicked != nil {
+				panic(panicked)
+			}
+		}
+	}
+}
+
+// StateMiddleware moves the State to Running for the duration of the command and to Stopping, or
+// Errored if the command panicked or errored, once it is done. Shutdown completes the move to
+// Stopped after running the shutdown hooks.
+func StateMiddleware() Middleware {
+	return func(next CmdFunc) CmdFunc {
+		return func(cmd *cobra.Command, args []string) {
+			state := CurrentState()
+			state.SetState(Running)
+			panicked := runGuarded(next, cmd, args)
+			if panicked != nil || state.State() == Errored {
+				state.SetState(Errored)
+			} else {
+				state.SetState(Stopping)
+			}
+			if panicked != nil {
+				panic(panicked)
+			}
+		}
+	}
+}
+
+// VerboseMiddleware applies the boolean flag [flagName], when given, to State.SetLogging.
+func VerboseMiddleware(flagName string) Middleware {
+	return func(next CmdFunc) CmdFunc {
+		return func(cmd *cobra.Command, args []string) {
+			if flag := cmd.Flags().Lookup(flagName); flag != nil && flag.Changed {
+				verbose, err := cmd.Flags().GetBool(flagName)
+				CheckError(err, "Unable to read flag [%s]", flagName)
+				CurrentState().SetLogging(verbose)
+			}
+			next(cmd, args)
+		}
+	}
+}
+
+// ProfileMiddleware applies the string flag [flagName], when given, to State.SetConfigProfile.
+func ProfileMiddleware(flagName string) Middleware {
+	return func(next CmdFunc) CmdFunc {
+		return func(cmd *cobra.Command, args []string) {
+			if flag := cmd.Flags().Lookup(flagName); flag != nil && flag.Changed {
+				CurrentState().SetConfigProfile(flag.Value.String())
+			}
+			next(cmd, args)
+		}
+	}
+}
+
+// EventMiddleware publishes a CommandEvent on the EventBus when the command starts and finishes.
+func EventMiddleware() Middleware {
+	return func(next CmdFunc) CmdFunc {
+		return func(cmd *cobra.Command, args []string) {
+			name := GetFullCmdName(cmd)
+			redacted := RedactArgs(args, cmd.Flags())
+			EventBus.Send(NewCommandEvent(CMD_STARTED_EVENT, name, redacted, 0, nil))
+
+			start := time.Now()
+			panicked := runGuarded(next, cmd, args)
+			err := panicError(panicked)
+			if err == nil && CurrentState().State() == Errored {
+				err = CurrentState().LastError()
+			}
+
+			if err != nil {
+				EventBus.Send(NewCommandEvent(CMD_FAILED_EVENT, name, redacted, time.Since(start), err))
+			} else {
+				EventBus.Send(NewCommandEvent(CMD_FINISHED_EVENT, name, redacted, time.Since(start), nil))
+			}
+			if panicked != nil {
+				panic(panicked)
+			}
+		}
+	}
+}
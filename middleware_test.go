@@ -0,0 +1,102 @@
+package golang_utils
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next CmdFunc) CmdFunc {
+		return func(cmd *cobra.Command, args []string) {
+			*calls = append(*calls, "before "+name)
+			next(cmd, args)
+			*calls = append(*calls, "after "+name)
+		}
+	}
+}
+
+func TestMiddlewareRunsInRegistrationOrder(t *testing.T) {
+	calls := make([]string, 0)
+	cmd := CommandBuilder("suzy").
+		DisableTracking().
+		Use(recordingMiddleware("outer", &calls), recordingMiddleware("inner", &calls)).
+		SetPreRun(func(*cobra.Command, []string) { calls = append(calls, "pre") }).
+		SetRun(func(*cobra.Command, []string) { calls = append(calls, "run") }).
+		SetPostRun(func(*cobra.Command, []string) { calls = append(calls, "post") }).
+		Build()
+	cmd.SetArgs([]string{})
+	assert.NoError(t, cmd.Execute())
+
+	assert.Equal(
+		t, []string{"before outer", "before inner", "pre", "run", "post", "after inner", "after outer"}, calls,
+		"the middleware should wrap the pre and post run as well",
+	)
+}
+
+func TestStandardMiddleware(t *testing.T) {
+	Reset()
+	defer Reset()
+	state := CurrentState()
+	defer func() { state.SetLogging(false).SetState(Starting) }()
+
+	events := make([]string, 0)
+	EventBus.Register(
+		"cmd.", NewEmptyCommandEvent(), func(event Event) error {
+			events = append(events, event.Name())
+			return nil
+		},
+	)
+
+	var during ApplicationState
+	cmd := CommandBuilder("suzy").
+		DisableTracking().
+		AddFlags(
+			func(flags *pflag.FlagSet) {
+				flags.Bool(VERBOSE_FLAG_NAME, false, "verbose logging")
+			},
+		).
+		Use(StandardMiddleware()...).
+		SetRun(func(*cobra.Command, []string) { during = CurrentState().State() }).
+		Build()
+	cmd.SetArgs([]string{"--verbose"})
+	assert.NoError(t, cmd.Execute())
+
+	assert.Equal(t, ApplicationState(Running), during)
+	assert.Equal(t, ApplicationState(Stopping), state.State(), "Shutdown should be left to stop the State")
+	assert.True(t, state.Verbose(), "the verbose flag should have been applied to the State")
+	assert.Equal(t, []string{CMD_STARTED_EVENT, CMD_FINISHED_EVENT}, events)
+}
+
+func TestStateMiddlewareMarksPanicsAsErrored(t *testing.T) {
+	state := CurrentState()
+	defer state.SetState(Starting)
+
+	cmd := CommandBuilder("suzy").
+		DisableTracking().
+		Use(StateMiddleware()).
+		SetRun(func(*cobra.Command, []string) { panic("boom") }).
+		Build()
+	cmd.SetArgs([]string{})
+
+	assert.Panics(t, func() { _ = cmd.Execute() })
+	assert.Equal(t, ApplicationState(Errored), state.State())
+}
+
+func TestShutdownHooksRunWhileStopping(t *testing.T) {
+	state := withFreshState(t)
+	var during ApplicationState
+	state.OnShutdown(func() { during = state.State() })
+
+	err := CommandBuilder("suzy").
+		DisableTracking().
+		Use(StateMiddleware()).
+		SetRun(func(*cobra.Command, []string) {}).
+		Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, ApplicationState(Stopping), during)
+	assert.Equal(t, ApplicationState(Stopped), state.State())
+}
@@ -0,0 +1,359 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: prompt.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// PromptKind - The kind of input a Prompt asks for
+type PromptKind int
+
+const (
+	// PromptText - Free text, echoed as it is typed
+	PromptText PromptKind = iota
+	// PromptPassword - Free text that is not echoed
+	PromptPassword
+	// PromptSelect - One of a list of options
+	PromptSelect
+	// PromptConfirm - A yes/no answer
+	PromptConfirm
+)
+
+const maxPromptAttempts = 3
+
+var (
+	ErrNotInteractive = errors.New("input is required but the session is not interactive")
+
+	activePrompter *Prompter
+	prompterLock   sync.Mutex
+)
+
+// Prompt - Describes the value asked for when a required flag or argument is missing
+type Prompt struct {
+	Message  string
+	Kind     PromptKind
+	Options  []string
+	Default  string
+	Validate *Regex
+}
+
+// Prompter - Reads answers to prompts from an input stream
+type Prompter struct {
+	in          *bufio.Reader
+	inFile      *os.File
+	out         io.Writer
+	interactive bool
+}
+
+// NewPrompter creates a prompter over the given streams. A prompter created over an injected
+// (non terminal) reader is always interactive so prompts can be scripted in tests.
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	p := &Prompter{
+		in:          bufio.NewReader(in),
+		out:         out,
+		interactive: true,
+	}
+	if f, ok := in.(*os.File); ok {
+		p.inFile = f
+		p.interactive = term.IsTerminal(int(f.Fd()))
+	}
+	return p
+}
+
+// CurrentPrompter returns the prompter used by built commands, by default one over stdin/stdout.
+func CurrentPrompter() *Prompter {
+	prompterLock.Lock()
+	defer prompterLock.Unlock()
+	if activePrompter == nil {
+		activePrompter = NewPrompter(os.Stdin, os.Stdout)
+	}
+	return activePrompter
+}
+
+// SetPrompter replaces the prompter used by built commands. Passing nil restores the default.
+func SetPrompter(p *Prompter) {
+	prompterLock.Lock()
+	defer prompterLock.Unlock()
+	activePrompter = p
+}
+
+func (p *Prompter) Interactive() bool {
+	return p.interactive
+}
+
+// Ask dispatches to the prompt method for the prompt's kind. Invalid answers are asked again
+// a limited number of times before an error is returned.
+func (p *Prompter) Ask(prompt *Prompt) (string, error) {
+	switch prompt.Kind {
+	case PromptPassword:
+		return p.Password(prompt.Message, prompt.Validate)
+	case PromptSelect:
+		return p.Select(prompt.Message, prompt.Options, prompt.Default)
+	case PromptConfirm:
+		confirmed, err := p.Confirm(prompt.Message, prompt.Default == "true")
+		return strconv.FormatBool(confirmed), err
+	default:
+		return p.Text(prompt.Message, prompt.Default, prompt.Validate)
+	}
+}
+
+func (p *Prompter) Text(msg string, defaultValue string, validate *Regex) (string, error) {
+	label := msg
+	if IsNotEmpty(defaultValue) {
+		label = fmt.Sprintf("%s [%s]", msg, defaultValue)
+	}
+	return p.askUntilValid(
+		label, validate, func() (string, error) {
+			answer, err := p.readLine()
+			if err == nil && answer == "" {
+				answer = defaultValue
+			}
+			return answer, err
+		},
+	)
+}
+
+func (p *Prompter) Password(msg string, validate *Regex) (string, error) {
+	return p.askUntilValid(
+		msg, validate, func() (string, error) {
+			if p.inFile != nil && term.IsTerminal(int(p.inFile.Fd())) {
+				secret, err := term.ReadPassword(int(p.inFile.Fd()))
+				fmt.Fprintln(p.out)
+				return string(secret), err
+			}
+			return p.readLine()
+		},
+	)
+}
+
+// Select asks for one of the options, either by its number or its value.
+func (p *Prompter) Select(msg string, options []string, defaultValue string) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("no options to select from for [%s]", msg)
+	}
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+	label := msg
+	if IsNotEmpty(defaultValue) {
+		label = fmt.Sprintf("%s [%s]", msg, defaultValue)
+	}
+	for attempt := 0; attempt < maxPromptAttempts; attempt++ {
+		if !p.interactive {
+			return "", ErrNotInteractive
+		}
+		fmt.Fprintf(p.out, "%s: ", label)
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = defaultValue
+		}
+		if index, err := strconv.Atoi(answer); err == nil && index > 0 && index <= len(options) {
+			return options[index-1], nil
+		}
+		if Contains(options, answer) {
+			return answer, nil
+		}
+		fmt.Fprintf(p.out, "[%s] is not one of the options\n", answer)
+	}
+	return "", fmt.Errorf("no valid option selected for [%s]", msg)
+}
+
+func (p *Prompter) Confirm(msg string, defaultValue bool) (bool, error) {
+	choices := "y/N"
+	if defaultValue {
+		choices = "Y/n"
+	}
+	for attempt := 0; attempt < maxPromptAttempts; attempt++ {
+		if !p.interactive {
+			return false, ErrNotInteractive
+		}
+		fmt.Fprintf(p.out, "%s [%s]: ", msg, choices)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultValue, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintf(p.out, "please answer y or n\n")
+	}
+	return false, fmt.Errorf("no confirmation given for [%s]", msg)
+}
+
+func (p *Prompter) askUntilValid(label string, validate *Regex, read func() (string, error)) (string, error) {
+	for attempt := 0; attempt < maxPromptAttempts; attempt++ {
+		if !p.interactive {
+			return "", ErrNotInteractive
+		}
+		fmt.Fprintf(p.out, "%s: ", label)
+		answer, err := read()
+		if err != nil {
+			return "", err
+		}
+		if validate == nil || validate.Matches(answer) {
+			return answer, nil
+		}
+		fmt.Fprintf(p.out, "invalid value, expected input matching [%s]\n", validate.Pattern)
+	}
+	return "", fmt.Errorf("no valid input given for [%s]", label)
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// addPrompting defers arg validation until missing input has been prompted for and hands the
+// completed args to the pre run and run functions.
+func addPrompting(cmd *cobra.Command, config *CmdConfig) {
+	var prompted []string
+	validate := cmd.Args
+	preRun := cmd.PreRun
+	run := cmd.Run
+
+	cmd.Args = func(c *cobra.Command, args []string) error {
+		err := validateArgs(validate, c, args)
+		if err != nil && len(args) < len(config.argPrompts) && CurrentPrompter().Interactive() {
+			return nil //Missing args are prompted for before the command runs
+		}
+		return err
+	}
+
+	cmd.PreRun = nil
+	cmd.PreRunE = func(c *cobra.Command, args []string) error {
+		full, err := promptForMissing(c, args, config)
+		if err != nil {
+			return err
+		}
+		if err = validateArgs(validate, c, full); err != nil {
+			return err
+		}
+		prompted = full
+		if preRun != nil {
+			preRun(c, full)
+		}
+		return nil
+	}
+
+	if run != nil {
+		cmd.Run = func(c *cobra.Command, args []string) {
+			if prompted != nil {
+				args = prompted
+				prompted = nil
+			}
+			run(c, args)
+		}
+	}
+}
+
+func validateArgs(validate cobra.PositionalArgs, cmd *cobra.Command, args []string) error {
+	if validate == nil {
+		return nil
+	}
+	return validate(cmd, args)
+}
+
+// flagPrompt returns the configured prompt for a flag or one derived from the flag itself.
+func flagPrompt(flag *pflag.Flag, prompts map[string]*Prompt) *Prompt {
+	if prompt, ok := prompts[flag.Name]; ok {
+		return prompt
+	}
+	prompt := &Prompt{Message: flag.Name, Kind: PromptText}
+	if IsNotEmpty(flag.Usage) {
+		prompt.Message = fmt.Sprintf("%s (%s)", flag.Name, flag.Usage)
+	}
+	if IsSecretKey(flag.Name) {
+		prompt.Kind = PromptPassword
+	}
+	return prompt
+}
+
+// promptForMissing asks for every required flag that was not given and for any positional args
+// missing from the end of args. It returns the completed list of args.
+func promptForMissing(cmd *cobra.Command, args []string, config *CmdConfig) ([]string, error) {
+	prompter := CurrentPrompter()
+
+	var missing []*pflag.Flag
+	cmd.Flags().VisitAll(
+		func(flag *pflag.Flag) {
+			if required, ok := flag.Annotations[cobra.BashCompOneRequiredFlag]; ok &&
+				len(required) > 0 && required[0] == "true" && !flag.Changed {
+				missing = append(missing, flag)
+			}
+		},
+	)
+
+	if (len(missing) > 0 || len(args) < len(config.argPrompts)) && !prompter.Interactive() {
+		//Let cobra report the missing input
+		return args, nil
+	}
+
+	for _, flag := range missing {
+		answer, err := prompter.Ask(flagPrompt(flag, config.flagPrompts))
+		if err != nil {
+			return args, fmt.Errorf("unable to read a value for flag [%s]: %w", flag.Name, err)
+		}
+		if err = cmd.Flags().Set(flag.Name, answer); err != nil {
+			return args, fmt.Errorf("invalid value for flag [%s]: %w", flag.Name, err)
+		}
+	}
+
+	for i := len(args); i < len(config.argPrompts); i++ {
+		answer, err := prompter.Ask(config.argPrompts[i])
+		if err != nil {
+			return args, fmt.Errorf("unable to read a value for [%s]: %w", config.argPrompts[i].Message, err)
+		}
+		args = append(args, answer)
+	}
+	return args, nil
+}
@@ -0,0 +1,87 @@
+package golang_utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPromptTestCmd(got *[]string, user *string) *cobra.Command {
+	return CommandBuilder("suzy").
+		DisableTracking().
+		AddFlags(
+			func(flags *pflag.FlagSet) {
+				flags.String("user", "", "the user name")
+			},
+		).
+		SetRequiredFlags("user").
+		SetArgValidations(cobra.ExactArgs(2)).
+		SetArgPrompts(
+			&Prompt{Message: "color", Kind: PromptSelect, Options: []string{"red", "green"}},
+			&Prompt{Message: "count", Validate: NewRegex(`^\d+$`)},
+		).
+		SetRun(
+			func(cmd *cobra.Command, args []string) {
+				*got = args
+				*user, _ = cmd.Flags().GetString("user")
+			},
+		).
+		Build()
+}
+
+func TestPromptsForMissingFlagsAndArgs(t *testing.T) {
+	defer SetPrompter(nil)
+	SetPrompter(NewPrompter(strings.NewReader("suzy\n2\nabc\n42\n"), new(bytes.Buffer)))
+
+	var got []string
+	var user string
+	cmd := newPromptTestCmd(&got, &user)
+	cmd.SetArgs([]string{})
+	assert.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "suzy", user, "the missing required flag should have been prompted for")
+	assert.Equal(t, []string{"green", "42"}, got, "invalid input should be asked for again")
+}
+
+func TestPromptsOnlyForArgsNotGiven(t *testing.T) {
+	defer SetPrompter(nil)
+	SetPrompter(NewPrompter(strings.NewReader("7\n"), new(bytes.Buffer)))
+
+	var got []string
+	var user string
+	cmd := newPromptTestCmd(&got, &user)
+	cmd.SetArgs([]string{"red", "--user", "q"})
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"red", "7"}, got)
+	assert.Equal(t, "q", user)
+}
+
+func TestNonInteractiveRunsFailOnMissingInput(t *testing.T) {
+	defer SetPrompter(nil)
+	p := NewPrompter(strings.NewReader(""), new(bytes.Buffer))
+	p.interactive = false
+	SetPrompter(p)
+
+	var got []string
+	var user string
+	cmd := newPromptTestCmd(&got, &user)
+	cmd.SetArgs([]string{"red", "1"})
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetOut(new(bytes.Buffer))
+	assert.ErrorContains(t, cmd.Execute(), "required flag(s) \"user\" not set")
+}
+
+func TestConfirm(t *testing.T) {
+	p := NewPrompter(strings.NewReader("maybe\ny\n\n"), new(bytes.Buffer))
+	confirmed, err := p.Confirm("sure?", false)
+	assert.NoError(t, err)
+	assert.True(t, confirmed)
+
+	confirmed, err = p.Confirm("sure?", false)
+	assert.NoError(t, err)
+	assert.False(t, confirmed, "an empty answer should use the default")
+}
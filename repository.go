@@ -0,0 +1,312 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: repository.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DEFAULT_PAGE_SIZE is the page size List uses when the Page doesn't give one
+const DEFAULT_PAGE_SIZE = 50
+
+// ErrNotFound is returned when a record looked up or deleted by id doesn't exist. It is gorm's
+// ErrRecordNotFound so either can be checked with errors.Is.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// ErrDBNotOpen is returned by repositories whose PersistenceContext hasn't been opened.
+var ErrDBNotOpen = errors.New("database is not open")
+
+// Query - Conditions, ordering and limits for Find, Count, Exists and Each. A nil Query matches
+// every record.
+type Query struct {
+	conditions []queryCondition
+	order      []string
+	limit      int
+	offset     int
+}
+
+type queryCondition struct {
+	condition any
+	args      []any
+}
+
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Where adds a condition that records must also match. It takes anything gorm's Where does: a
+// string with ? placeholders and its args, a struct or a map.
+func (q *Query) Where(condition any, args ...any) *Query {
+	q.conditions = append(q.conditions, queryCondition{condition, args})
+	return q
+}
+
+// OrderBy adds a sort order such as "start_time desc".
+func (q *Query) OrderBy(order string) *Query {
+	q.order = append(q.order, order)
+	return q
+}
+
+func (q *Query) Limit(limit int) *Query {
+	q.limit = limit
+	return q
+}
+
+func (q *Query) Offset(offset int) *Query {
+	q.offset = offset
+	return q
+}
+
+func (q *Query) apply(tx *gorm.DB) *gorm.DB {
+	if q == nil {
+		return tx
+	}
+	for _, condition := range q.conditions {
+		tx = tx.Where(condition.condition, condition.args...)
+	}
+	for _, order := range q.order {
+		tx = tx.Order(order)
+	}
+	if q.limit > 0 {
+		tx = tx.Limit(q.limit)
+	}
+	if q.offset > 0 {
+		tx = tx.Offset(q.offset)
+	}
+	return tx
+}
+
+// Page - Which page of records List returns. Pages are numbered from 1 and ordered by primary key
+// unless Order is given.
+type Page struct {
+	Number int
+	Size   int
+	Order  string
+	Query  *Query
+}
+
+// PageOf - A page of records and the total number of records across all pages
+type PageOf[T any] struct {
+	Items  []T
+	Number int
+	Size   int
+	Total  int64
+}
+
+// Pages returns how many pages of Size the Total records fill.
+func (p *PageOf[T]) Pages() int {
+	if p.Size <= 0 {
+		return 0
+	}
+	return int((p.Total + int64(p.Size) - 1) / int64(p.Size))
+}
+
+// Repository - Reads and writes the records of entity T through a PersistenceContext. Every method
+// returns its errors rather than logging them.
+type Repository[T any] struct {
+	context *PersistenceContext
+}
+
+func NewRepository[T any](context *PersistenceContext) *Repository[T] {
+	return &Repository[T]{context: context}
+}
+
+// Get returns the record with the primary key id or ErrNotFound.
+func (r *Repository[T]) Get(id any) (*T, error) {
+	db, err := r.db()
+	if err != nil {
+		return nil, err
+	}
+	record := new(T)
+	if err = db.Where(primaryKeyIs(id)).First(record).Error; err != nil {
+		return nil, fmt.Errorf("unable to get %T [%v]: %w", *record, id, err)
+	}
+	return record, nil
+}
+
+// Find returns the records matching query.
+func (r *Repository[T]) Find(query *Query) ([]T, error) {
+	db, err := r.db()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]T, 0)
+	if err = query.apply(db.Model(new(T))).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("unable to find %T records: %w", *new(T), err)
+	}
+	return records, nil
+}
+
+// List returns one page of the records matching page.Query.
+func (r *Repository[T]) List(page Page) (*PageOf[T], error) {
+	if page.Number < 1 {
+		page.Number = 1
+	}
+	if page.Size <= 0 {
+		page.Size = DEFAULT_PAGE_SIZE
+	}
+	total, err := r.Count(page.Query)
+	if err != nil {
+		return nil, err
+	}
+	db, err := r.db()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := page.Query.apply(db.Model(new(T)))
+	if page.Order != "" {
+		tx = tx.Order(page.Order)
+	} else {
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}})
+	}
+	items := make([]T, 0, page.Size)
+	if err = tx.Limit(page.Size).Offset((page.Number - 1) * page.Size).Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("unable to list page %d of %T records: %w", page.Number, *new(T), err)
+	}
+	return &PageOf[T]{Items: items, Number: page.Number, Size: page.Size, Total: total}, nil
+}
+
+// Count returns how many records match query.
+func (r *Repository[T]) Count(query *Query) (int64, error) {
+	db, err := r.db()
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err = query.apply(db.Model(new(T))).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("unable to count %T records: %w", *new(T), err)
+	}
+	return count, nil
+}
+
+// Exists reports whether any record matches query.
+func (r *Repository[T]) Exists(query *Query) (bool, error) {
+	count, err := r.Count(query)
+	return count > 0, err
+}
+
+// Each streams the records matching query to fn one at a time, stopping at the first error fn returns.
+func (r *Repository[T]) Each(query *Query, fn func(record *T) error) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+	rows, err := query.apply(db.Model(new(T))).Rows()
+	if err != nil {
+		return fmt.Errorf("unable to read %T records: %w", *new(T), err)
+	}
+	defer func() { LogError(rows.Close(), "Error closing %T records", *new(T)) }()
+
+	for rows.Next() {
+		record := new(T)
+		if err = db.ScanRows(rows, record); err != nil {
+			return fmt.Errorf("unable to read %T record: %w", *record, err)
+		}
+		if err = fn(record); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Create inserts the record, filling in its primary key.
+func (r *Repository[T]) Create(record *T) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+	if err = db.Create(record).Error; err != nil {
+		return fmt.Errorf("unable to create %T: %w", *record, err)
+	}
+	return nil
+}
+
+// Save updates the record, or inserts it if it has no primary key yet.
+func (r *Repository[T]) Save(record *T) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+	if err = db.Save(record).Error; err != nil {
+		return fmt.Errorf("unable to save %T: %w", *record, err)
+	}
+	return nil
+}
+
+// Upsert inserts the record or, when it conflicts with an existing one on conflictColumns (the
+// primary key if none are given), updates every column of the existing record.
+func (r *Repository[T]) Upsert(record *T, conflictColumns ...string) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+	onConflict := clause.OnConflict{UpdateAll: true}
+	for _, column := range conflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	if err = db.Clauses(onConflict).Create(record).Error; err != nil {
+		return fmt.Errorf("unable to upsert %T: %w", *record, err)
+	}
+	return nil
+}
+
+// Delete removes the record with the primary key id or returns ErrNotFound.
+func (r *Repository[T]) Delete(id any) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+	tx := db.Where(primaryKeyIs(id)).Delete(new(T))
+	if tx.Error == nil && tx.RowsAffected == 0 {
+		tx.Error = ErrNotFound
+	}
+	if tx.Error != nil {
+		return fmt.Errorf("unable to delete %T [%v]: %w", *new(T), id, tx.Error)
+	}
+	return nil
+}
+
+// primaryKeyIs matches the primary key against id as a value. Passing id to gorm directly would
+// treat a string id as raw SQL.
+func primaryKeyIs(id any) clause.Eq {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}, Value: id}
+}
+
+func (r *Repository[T]) db() (*gorm.DB, error) {
+	if r.context == nil || r.context.DB == nil {
+		return nil, ErrDBNotOpen
+	}
+	return r.context.DB, nil
+}
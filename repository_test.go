@@ -0,0 +1,110 @@
+package golang_utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type widget struct {
+	ID    uint   `gorm:"primarykey"`
+	Name  string `gorm:"uniqueIndex"`
+	Color string
+}
+
+func newWidgetRepository(t *testing.T) *Repository[widget] {
+	context := NewPersistenceContext(NewPersistenceConfig("widgets.db", t.TempDir(), []any{&widget{}}))
+	context.OpenDB()
+	t.Cleanup(func() { _ = context.Close() })
+	return NewRepository[widget](context)
+}
+
+func TestRepositoryReadsAndWrites(t *testing.T) {
+	widgets := newWidgetRepository(t)
+	for i := 1; i <= 5; i++ {
+		color := "red"
+		if i%2 == 0 {
+			color = "blue"
+		}
+		assert.NoError(t, widgets.Create(&widget{Name: fmt.Sprintf("w%d", i), Color: color}))
+	}
+
+	found, err := widgets.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "w2", found.Name)
+	_, err = widgets.Get(99)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	reds, err := widgets.Find(NewQuery().Where("color = ?", "red").OrderBy("name desc"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"w5", "w3", "w1"}, []string{reds[0].Name, reds[1].Name, reds[2].Name})
+
+	page, err := widgets.List(Page{Number: 2, Size: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), page.Total)
+	assert.Equal(t, 3, page.Pages())
+	assert.Equal(t, []widget{{3, "w3", "red"}, {4, "w4", "blue"}}, page.Items)
+
+	count, err := widgets.Count(NewQuery().Where(&widget{Color: "blue"}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	exists, err := widgets.Exists(NewQuery().Where("name = ?", "w9"))
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.NoError(t, widgets.Upsert(&widget{Name: "w1", Color: "green"}, "name"))
+	updated, err := widgets.Find(NewQuery().Where("name = ?", "w1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "green", updated[0].Color)
+
+	assert.NoError(t, widgets.Delete(5))
+	assert.ErrorIs(t, widgets.Delete(5), ErrNotFound)
+
+	names := make([]string, 0)
+	stop := errors.New("stop")
+	err = widgets.Each(
+		NewQuery().OrderBy("id"), func(w *widget) error {
+			names = append(names, w.Name)
+			if len(names) == 3 {
+				return stop
+			}
+			return nil
+		},
+	)
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, []string{"w1", "w2", "w3"}, names)
+}
+
+type tag struct {
+	Key   string `gorm:"primarykey"`
+	Label string
+}
+
+func TestRepositoryTreatsStringIdsAsValues(t *testing.T) {
+	context := NewPersistenceContext(NewPersistenceConfig("tags.db", t.TempDir(), []any{&tag{}}))
+	context.OpenDB()
+	t.Cleanup(func() { _ = context.Close() })
+	tags := NewRepository[tag](context)
+	assert.NoError(t, tags.Create(&tag{Key: "abc", Label: "first"}))
+	assert.NoError(t, tags.Create(&tag{Key: "def", Label: "second"}))
+
+	found, err := tags.Get("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", found.Label)
+	_, err = tags.Get("1=1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, tags.Delete("1=1"), ErrNotFound)
+	count, err := tags.Count(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count, "a string id must never be used as a condition")
+	assert.NoError(t, tags.Delete("def"))
+}
+
+func TestRepositoryNeedsAnOpenDB(t *testing.T) {
+	_, err := NewRepository[widget](nil).Get(1)
+	assert.ErrorIs(t, err, ErrDBNotOpen)
+	assert.ErrorIs(t, NewPersistenceContext(NewPersistenceConfig("x.db", t.TempDir(), nil)).SaveE(&widget{}), ErrDBNotOpen)
+}
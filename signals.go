@@ -0,0 +1,100 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: signals.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+// FORCE_QUIT_EXIT_CODE - Exit code used when a second signal forces the application to quit (128 + SIGINT)
+const FORCE_QUIT_EXIT_CODE = 130
+
+var exitFunc = os.Exit
+
+// Execute runs the command tree with a context that is cancelled on SIGINT or SIGTERM.
+// The first signal moves the State to Stopping and cancels the context so the command can wind down.
+// A second signal forces the application to quit. Once the command returns the State is shutdown,
+// running all registered shutdown hooks. A command that reported an error through State.CheckError
+// without terminating makes Execute return that error, so the application can exit non zero.
+//
+// Signals are only handled this way when the tree is run through Execute, running the built command
+// with cobra's own Execute leaves them to the Go runtime.
+func Execute(root *cobra.Command) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state := CurrentState()
+	state.setContext(ctx)
+	state.resetRun()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	done := make(chan struct{})
+	defer close(done)
+	go handleSignals(state, signals, done, cancel)
+
+	err := root.ExecuteContext(ctx)
+	if err == nil {
+		err = state.LastError()
+	}
+	state.Shutdown()
+	return err
+}
+
+// Execute builds the command tree and runs it via Execute.
+func (cc *CmdConfig) Execute() error {
+	return Execute(cc.Build())
+}
+
+func handleSignals(state *State, signals <-chan os.Signal, done <-chan struct{}, cancel context.CancelFunc) {
+	select {
+	case sig := <-signals:
+		log.Warnf("Received [%s], stopping. Send it again to force quit", sig)
+		state.SetState(Stopping)
+		cancel()
+	case <-done:
+		return
+	}
+
+	select {
+	case sig := <-signals:
+		log.Errorf("Received [%s] while stopping, forcing quit", sig)
+		exitFunc(FORCE_QUIT_EXIT_CODE)
+	case <-done:
+	}
+}
@@ -0,0 +1,89 @@
+//go:build !windows
+
+package golang_utils
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func interruptWhenRunning(running <-chan struct{}) {
+	go func() {
+		<-running
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+	}()
+}
+
+func TestExecuteCancelsContextAndShutsDownOnSignal(t *testing.T) {
+	state := withFreshState(t)
+	tempDir := state.EnableTempDir().TempDir()
+	hookCalled := false
+	state.OnShutdown(func() { hookCalled = true })
+
+	running := make(chan struct{})
+	interruptWhenRunning(running)
+
+	err := CommandBuilder("suzy").
+		DisableTracking().
+		Use(StateMiddleware()).
+		SetRun(
+			func(cmd *cobra.Command, _ []string) {
+				close(running)
+				select {
+				case <-cmd.Context().Done():
+				case <-time.After(5 * time.Second):
+					t.Error("the command context was not cancelled")
+				}
+			},
+		).
+		Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, hookCalled, "shutdown hooks should run once the command returns")
+	assert.False(t, PathExists(tempDir), "the temp dir should be removed on shutdown")
+	assert.Equal(t, ApplicationState(Stopped), state.State())
+	assert.False(t, state.StopTime().IsZero())
+}
+
+func TestSecondSignalForcesQuit(t *testing.T) {
+	withFreshState(t)
+	exited := make(chan int, 1)
+	exitFunc = func(code int) { exited <- code }
+	defer func() { exitFunc = os.Exit }()
+
+	running := make(chan struct{})
+	interruptWhenRunning(running)
+
+	err := CommandBuilder("suzy").
+		DisableTracking().
+		SetRun(
+			func(cmd *cobra.Command, _ []string) {
+				close(running)
+				<-cmd.Context().Done()
+				_ = syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+				select {
+				case code := <-exited:
+					assert.Equal(t, FORCE_QUIT_EXIT_CODE, code)
+				case <-time.After(5 * time.Second):
+					t.Error("the second signal did not force a quit")
+				}
+			},
+		).
+		Execute()
+	assert.NoError(t, err)
+}
+
+func TestEveryExecuteShutsDown(t *testing.T) {
+	state := withFreshState(t)
+	for run := 1; run <= 2; run++ {
+		hookCalled := false
+		state.OnShutdown(func() { hookCalled = true })
+		assert.NoError(t, CommandBuilder("suzy").DisableTracking().SetRun(func(*cobra.Command, []string) {}).Execute())
+		assert.True(t, hookCalled, "run %d should run its shutdown hooks", run)
+	}
+}
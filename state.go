@@ -30,10 +30,13 @@
 package golang_utils
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
 	"path"
+	"strings"
 	"sync"
 	"time"
 
@@ -81,14 +84,38 @@ type State struct {
 	tempDir            string
 	dataDir            string
 	configFileName     string
+	configMigrations   []*ConfigMigration
+	configProfile      *string
 	appName            string
 	version            string
 	buildDate          string
 	commitSha          string
 	errored            bool
+	lastErr            error
+	runHistory         bool
+	currentRun         *CommandRun
+	ctx                context.Context
+	shutdownHooks      []func()
+	shutdownOnce       sync.Once
 	PersistenceContext *PersistenceContext
 }
 
+// VersionInfo - Build information for the application as reported by the version command
+type VersionInfo struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	CommitSha string `json:"commitSha"`
+	BuildDate string `json:"buildDate"`
+}
+
+// Fprint writes the build information to w.
+func (v VersionInfo) Fprint(w io.Writer) {
+	fmt.Fprintf(w, "Name: %s\n", v.Name)
+	fmt.Fprintf(w, "Version: %s\n", v.Version)
+	fmt.Fprintf(w, "Commit SHA: %s\n", v.CommitSha)
+	fmt.Fprintf(w, "Build Date: %s\n\n", v.BuildDate)
+}
+
 type ApplicationEvent struct {
 	Name     string
 	Type     string
@@ -123,6 +150,8 @@ func (s *State) init() {
 }
 
 func (s *State) SetState(newState ApplicationState) *State {
+	s.Lock()
+	defer s.Unlock()
 
 	//Capture new state
 	s.state = newState
@@ -136,17 +165,99 @@ func (s *State) SetState(newState ApplicationState) *State {
 	return s
 }
 
+func (s *State) State() ApplicationState {
+	s.Lock()
+	defer s.Unlock()
+	return s.state
+}
+
 func (s *State) EnablePersistence(config *PersistenceConfig) *State {
 	s.PersistenceContext = NewPersistenceContext(config)
 	s.PersistenceContext.OpenDB()
+	s.migrateRunHistory()
+	persistence := s.PersistenceContext
+	s.OnShutdown(
+		func() {
+			LogError(persistence.Close(), "Error closing database [%s]", persistence.DBFile.AbsFilePath())
+		},
+	)
 	return s
 }
 
 func (s *State) EnableTempDir() *State {
-	s.tempDir = CreateUniqueTempDir(s.appName).AbsFilePath()
+	tempDir := CreateUniqueTempDir(s.appName).AbsFilePath()
+	s.tempDir = tempDir
+	s.OnShutdown(
+		func() {
+			RemoveDir(tempDir)
+		},
+	)
 	return s
 }
 
+// OnShutdown registers a hook that is run when the State is shutdown. Hooks run in reverse order of registration.
+func (s *State) OnShutdown(hook func()) *State {
+	s.Lock()
+	defer s.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, hook)
+	return s
+}
+
+// Shutdown moves the State through Stopping to Stopped, running the shutdown hooks in between.
+// Only the first call of each Execute has any effect.
+func (s *State) Shutdown() {
+	s.shutdownOnce.Do(
+		func() {
+			s.SetState(Stopping)
+
+			s.Lock()
+			hooks := s.shutdownHooks
+			s.shutdownHooks = nil
+			s.Unlock()
+
+			for i := len(hooks) - 1; i >= 0; i-- {
+				runShutdownHook(hooks[i])
+			}
+			s.SetState(Stopped)
+		},
+	)
+}
+
+// resetRun prepares the State for a new Execute of the command tree: the next Shutdown runs again
+// and the error of an earlier run is forgotten.
+func (s *State) resetRun() {
+	s.Lock()
+	defer s.Unlock()
+	s.shutdownOnce = sync.Once{}
+	s.lastErr = nil
+}
+
+func runShutdownHook(hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Shutdown hook failed. Details: %v", r)
+		}
+	}()
+	hook()
+}
+
+// Context returns the context the running command was executed with. It is cancelled when
+// the application receives a stop signal.
+func (s *State) Context() context.Context {
+	s.Lock()
+	defer s.Unlock()
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+func (s *State) setContext(ctx context.Context) {
+	s.Lock()
+	defer s.Unlock()
+	s.ctx = ctx
+}
+
 func (s *State) EnableBaseDataDir(path string) *State {
 	s.dataDir = CreateDir(path).AbsFilePath()
 	return s
@@ -166,6 +277,8 @@ func (s *State) StartTime() time.Time {
 }
 
 func (s *State) StopTime() time.Time {
+	s.Lock()
+	defer s.Unlock()
 	return s.stopTime
 }
 
@@ -182,12 +295,23 @@ func (s *State) WorkDir() string {
 }
 
 func (s *State) Errored() bool {
+	s.Lock()
+	defer s.Unlock()
 	return s.errored
 }
 
+// LastError returns the last error passed to State.CheckError during the current run, if any.
+func (s *State) LastError() error {
+	s.Lock()
+	defer s.Unlock()
+	return s.lastErr
+}
+
 func (s *State) Verbose() bool { return s.verbose }
 
 func (s *State) Duration() time.Duration {
+	s.Lock()
+	defer s.Unlock()
 	if s.state == Stopped {
 		return s.stopTime.Sub(s.startTime)
 	}
@@ -196,11 +320,15 @@ func (s *State) Duration() time.Duration {
 
 func (s *State) CheckError(terminate bool, err error, msg string, args ...any) {
 	if err != nil {
+		s.Lock()
 		s.state = Errored
+		s.errored = true
+		s.lastErr = err
+		s.Unlock()
 		if terminate {
-			CheckError(err, msg, args)
+			CheckError(err, msg, args...)
 		} else {
-			LogError(err, msg, args)
+			LogError(err, msg, args...)
 		}
 	}
 }
@@ -262,8 +390,9 @@ func (s *State) SetCmd(cmd *cobra.Command) *State {
 	return s
 }
 
-func (s *State) TrackCmd(cmd *cobra.Command, _ []string) {
+func (s *State) TrackCmd(cmd *cobra.Command, args []string) {
 	s.SetCmd(cmd)
+	s.StartRun(cmd, args)
 }
 
 func (s *State) FullCommand() string {
@@ -310,10 +439,33 @@ func (s *State) GetFromState(name string) any {
 
 func (s *State) InitConfig(defaults Properties) *State {
 	s.config = NewConfiguration(s.configFile(), s.useHomeDir, defaults)
+	if !IsEmpty(s.appName) {
+		s.config.SetEnvPrefix(envPrefixRegex.ReplaceAllString(strings.ToUpper(s.appName), "_"))
+	}
+	s.config.AddMigrations(s.configMigrations...)
+	if s.configProfile != nil {
+		s.config.SetProfile(*s.configProfile)
+	}
 	s.config.Load("")
 	return s
 }
 
+// SetConfigMigrations registers the migrations run against the app's config files by InitConfig.
+func (s *State) SetConfigMigrations(migrations ...*ConfigMigration) *State {
+	s.configMigrations = migrations
+	return s
+}
+
+// SetConfigProfile selects the config profile used by the app, reloading the config if it is
+// already loaded. See Configuration.SetProfile.
+func (s *State) SetConfigProfile(name string) *State {
+	s.configProfile = &name
+	if s.config != nil {
+		s.config.SetProfile(name)
+	}
+	return s
+}
+
 func (s *State) DefaultConfig() *State {
 	s.config.Default()
 	return s
@@ -335,11 +487,37 @@ func (s *State) Config() *Configuration {
 	return s.config
 }
 
+// WatchConfig reloads the app's config whenever its file changes until the State shuts down.
+// See Configuration.Watch.
+func (s *State) WatchConfig(validate ConfigValidator) *State {
+	watcher, err := s.Config().Watch(DEFAULT_RELOAD_DEBOUNCE, validate)
+	if err != nil {
+		LogError(err, "Unable to watch the config for [%s]", s.appName)
+		return s
+	}
+	s.OnShutdown(watcher.Stop)
+	return s
+}
+
+func (s *State) AppName() string {
+	return s.appName
+}
+
+func (s *State) Version() string {
+	return s.version
+}
+
+func (s *State) VersionInfo() VersionInfo {
+	return VersionInfo{
+		Name:      s.appName,
+		Version:   s.version,
+		CommitSha: s.commitSha,
+		BuildDate: s.buildDate,
+	}
+}
+
 func (s *State) PrintVersion() {
-	fmt.Printf("Name: %s\n", s.appName)
-	fmt.Printf("Version: %s\n", s.version)
-	fmt.Printf("Commit SHA: %s\n", s.commitSha)
-	fmt.Printf("Build Date: %s\n\n", s.buildDate)
+	s.VersionInfo().Fprint(os.Stdout)
 }
 
 func (s *State) configFile() string {
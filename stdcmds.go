@@ -0,0 +1,454 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright © 2026 Jonathan Newell <jonnewell@mac.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ * Filename: stdcmds.go
+ * Last Modified: 10/18/26, 9:00 AM
+ * Modified By: newellj
+ *
+ */
+
+package golang_utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	JSON_FLAG_NAME       = "json"
+	DOCS_DIR_FLAG_NAME   = "dir"
+	DEFAULT_DOCS_DIR     = "docs"
+	FORMAT_FLAG_NAME     = "format"
+	SOURCES_FLAG_NAME    = "sources"
+	UNREDACTED_FLAG_NAME = "unredacted"
+	REPLACE_FLAG_NAME    = "replace"
+)
+
+// AttachStandardCommands adds the version, config, docs and completion commands to the root command.
+// All of them operate on the current State. Cobra's default completion command is replaced.
+func AttachStandardCommands(root *cobra.Command) *cobra.Command {
+	root.CompletionOptions.DisableDefaultCmd = true
+	root.AddCommand(
+		VersionCommandBuilder().Build(),
+		ConfigCommandBuilder().Build(),
+		DocsCommandBuilder().Build(),
+		CompletionCommandBuilder().Build(),
+	)
+	return root
+}
+
+func VersionCommandBuilder() *CmdConfig {
+	return CommandBuilder("version").
+		SetShortDescription("Print the version information").
+		SetArgValidations(cobra.NoArgs).
+		AddFlags(
+			func(flags *pflag.FlagSet) {
+				flags.Bool(JSON_FLAG_NAME, false, "print the version information as json")
+			},
+		).
+		SetRun(
+			func(cmd *cobra.Command, args []string) {
+				asJson, err := cmd.Flags().GetBool(JSON_FLAG_NAME)
+				CheckError(err, "Unable to read flag [%s]", JSON_FLAG_NAME)
+				if !asJson {
+					CurrentState().VersionInfo().Fprint(cmd.OutOrStdout())
+					return
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				CheckError(encoder.Encode(CurrentState().VersionInfo()), "Unable to write version information")
+			},
+		)
+}
+
+// ConfigCommandBuilder builds the config command. Its sub commands apply the persistent --profile
+// flag before touching the configuration.
+func ConfigCommandBuilder() *CmdConfig {
+	return useProfileFlag(
+		configCommandBuilder().
+			AddPersistentFlags(
+				func(flags *pflag.FlagSet) {
+					flags.String(PROFILE_FLAG_NAME, "", "the config profile to use")
+				},
+			).
+			SetFlagCompletion(PROFILE_FLAG_NAME, ConfigProfileCompletions()),
+	)
+}
+
+func configCommandBuilder() *CmdConfig {
+	return CommandBuilder("config").
+		SetShortDescription("View and manage the configuration").
+		AddSubCommands(
+			CommandBuilder("show").
+				SetShortDescription("Print all configuration properties").
+				SetArgValidations(cobra.NoArgs).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						CurrentState().Config().Fprint(cmd.OutOrStdout())
+					},
+				),
+			CommandBuilder("get <property>").
+				SetShortDescription("Print the value of a configuration property").
+				SetArgValidations(cobra.ExactArgs(1)).
+				SetArgCompletions(ConfigKeyCompletions()).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						config := CurrentState().Config()
+						if !config.IsSet(args[0]) {
+							fmt.Fprintf(cmd.OutOrStdout(), "no property [%s] currently defined\n", args[0])
+							return
+						}
+						if value, ok := config.GetValue(args[0]).(map[string]any); ok {
+							Fprint(cmd.OutOrStdout(), value, "---- %s ----", args[0])
+							return
+						}
+						fmt.Fprintln(cmd.OutOrStdout(), config.GetValue(args[0]))
+					},
+				),
+			CommandBuilder("set <property> <value>").
+				SetShortDescription("Add or update a configuration property").
+				SetArgValidations(cobra.ExactArgs(2)).
+				SetArgCompletions(ConfigKeyCompletions(), nil).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						CurrentState().UpdateConfigProperty(args[0], args[1])
+					},
+				),
+			CommandBuilder("set-secret <property> [value]").
+				SetShortDescription("Encrypt and store a secret configuration property, prompting for the value if not given").
+				SetArgValidations(cobra.RangeArgs(1, 2)).
+				SetArgCompletions(ConfigKeyCompletions(), nil).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						value := ""
+						if len(args) > 1 {
+							value = args[1]
+						} else {
+							var err error
+							value, err = CurrentPrompter().Password(fmt.Sprintf("value for %s", args[0]), nil)
+							if err != nil {
+								CurrentState().CheckError(false, err, "Unable to read the value for [%s]", args[0])
+								return
+							}
+						}
+						CurrentState().CheckError(
+							false, CurrentState().Config().UpdateSecret(args[0], value), "Unable to store secret [%s]", args[0],
+						)
+					},
+				),
+			CommandBuilder("init").
+				SetShortDescription("Write a configuration file populated with the defaults").
+				SetArgValidations(cobra.NoArgs).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						CurrentState().DefaultConfig()
+					},
+				),
+			CommandBuilder("explain <property>").
+				SetShortDescription("Show which config layer supplied the value of a configuration property").
+				SetArgValidations(cobra.ExactArgs(1)).
+				SetArgCompletions(ConfigKeyCompletions()).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						fmt.Fprintln(cmd.OutOrStdout(), CurrentState().Config().Explain(args[0]))
+					},
+				),
+			CommandBuilder("backups").
+				SetShortDescription("List the backups of the configuration file, newest first").
+				SetArgValidations(cobra.NoArgs).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						for i, backup := range CurrentState().Config().Backups() {
+							fmt.Fprintf(cmd.OutOrStdout(), "%d: %s\n", i+1, backup)
+						}
+					},
+				),
+			CommandBuilder("restore [n]").
+				SetShortDescription("Restore the configuration file from its nth newest backup (default 1)").
+				SetArgValidations(cobra.MaximumNArgs(1)).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						n := 1
+						if len(args) > 0 {
+							var err error
+							if n, err = strconv.Atoi(args[0]); err != nil {
+								CurrentState().CheckError(false, err, "Invalid backup number [%s]", args[0])
+								return
+							}
+						}
+						CurrentState().CheckError(false, CurrentState().Config().Restore(n), "Unable to restore the config")
+					},
+				),
+			CommandBuilder("path").
+				SetShortDescription("Print the location of the configuration file").
+				SetArgValidations(cobra.NoArgs).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						fmt.Fprintln(cmd.OutOrStdout(), CurrentState().Config().Path())
+					},
+				),
+			CommandBuilder("export").
+				SetShortDescription("Print the effective configuration with secrets redacted").
+				SetArgValidations(cobra.NoArgs).
+				AddFlags(
+					func(flags *pflag.FlagSet) {
+						flags.String(FORMAT_FLAG_NAME, "yaml", "the format to print, yaml or json")
+						flags.Bool(SOURCES_FLAG_NAME, false, "print the layer that supplied each value")
+						flags.Bool(UNREDACTED_FLAG_NAME, false, "print secret values as stored, encrypted values stay encrypted")
+					},
+				).
+				SetFlagCompletion(FORMAT_FLAG_NAME, StaticCompletions("yaml", "json")).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						format, err := cmd.Flags().GetString(FORMAT_FLAG_NAME)
+						CheckError(err, "Unable to read flag [%s]", FORMAT_FLAG_NAME)
+						sources, err := cmd.Flags().GetBool(SOURCES_FLAG_NAME)
+						CheckError(err, "Unable to read flag [%s]", SOURCES_FLAG_NAME)
+						unredacted, err := cmd.Flags().GetBool(UNREDACTED_FLAG_NAME)
+						CheckError(err, "Unable to read flag [%s]", UNREDACTED_FLAG_NAME)
+						CurrentState().CheckError(
+							false,
+							CurrentState().Config().WriteExport(
+								cmd.OutOrStdout(), ExportOptions{Format: format, Sources: sources, Redact: !unredacted},
+							),
+							"Unable to export the config",
+						)
+					},
+				),
+			CommandBuilder("import <file>").
+				SetShortDescription("Merge a configuration file into the configuration, or replace it with --replace").
+				SetArgValidations(cobra.ExactArgs(1)).
+				SetArgCompletions(FileGlobCompletions("*.yaml", "*.yml", "*.json", "*.toml")).
+				AddFlags(
+					func(flags *pflag.FlagSet) {
+						flags.Bool(REPLACE_FLAG_NAME, false, "replace the configuration instead of merging into it")
+					},
+				).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						replace, err := cmd.Flags().GetBool(REPLACE_FLAG_NAME)
+						CheckError(err, "Unable to read flag [%s]", REPLACE_FLAG_NAME)
+						CurrentState().CheckError(
+							false, CurrentState().Config().Import(args[0], replace), "Unable to import config [%s]", args[0],
+						)
+					},
+				),
+			CommandBuilder("diff [file]").
+				SetShortDescription("Show the settings that differ from the defaults, or from another configuration file").
+				SetArgValidations(cobra.MaximumNArgs(1)).
+				SetArgCompletions(FileGlobCompletions("*.yaml", "*.yml", "*.json", "*.toml")).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						config := CurrentState().Config()
+						diffs := config.DiffDefaults()
+						if len(args) > 0 {
+							var err error
+							if diffs, err = config.DiffFile(args[0]); err != nil {
+								CurrentState().CheckError(false, err, "Unable to compare the config")
+								return
+							}
+						}
+						printDifferences(cmd, diffs)
+					},
+				),
+			ConfigProfileCommandBuilder(),
+		)
+}
+
+// ConfigProfileCommandBuilder builds the `profile` command for listing, switching and comparing
+// config profiles.
+func ConfigProfileCommandBuilder() *CmdConfig {
+	return CommandBuilder("profile").
+		SetShortDescription("List, switch and compare configuration profiles").
+		AddSubCommands(
+			CommandBuilder("list").
+				SetShortDescription("List the configuration profiles, marking the active one with *").
+				SetArgValidations(cobra.NoArgs).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						config := CurrentState().Config()
+						for _, profile := range config.Profiles() {
+							marker := " "
+							if profile == config.Profile() {
+								marker = "*"
+							}
+							fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", marker, profile)
+						}
+					},
+				),
+			CommandBuilder("switch <profile>").
+				SetShortDescription("Make a profile the default by saving it in the configuration file").
+				SetArgValidations(cobra.ExactArgs(1)).
+				SetArgCompletions(ConfigProfileCompletions()).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						CurrentState().CheckError(
+							false, CurrentState().Config().SwitchProfile(args[0]), "Unable to switch to profile [%s]", args[0],
+						)
+					},
+				),
+			CommandBuilder("diff <profile> [other profile]").
+				SetShortDescription("Show the settings that differ between two profiles, or a profile and the base settings").
+				SetArgValidations(cobra.RangeArgs(1, 2)).
+				SetArgCompletions(ConfigProfileCompletions()).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						from, to := "", args[0]
+						if len(args) > 1 {
+							from, to = args[0], args[1]
+						}
+						diffs, err := CurrentState().Config().DiffProfiles(from, to)
+						if err != nil {
+							CurrentState().CheckError(false, err, "Unable to compare profiles")
+							return
+						}
+						printDifferences(cmd, diffs)
+					},
+				),
+		)
+}
+
+func printDifferences(cmd *cobra.Command, diffs []ConfigDifference) {
+	if len(diffs) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no differences")
+	}
+	for _, diff := range diffs {
+		fmt.Fprintln(cmd.OutOrStdout(), diff)
+	}
+}
+
+// useProfileFlag adds the ProfileMiddleware to cc and every command below it that runs.
+func useProfileFlag(cc *CmdConfig) *CmdConfig {
+	if cc.run != nil {
+		cc.Use(ProfileMiddleware(PROFILE_FLAG_NAME))
+	}
+	for _, sub := range cc.subCommands {
+		useProfileFlag(sub)
+	}
+	return cc
+}
+
+func DocsCommandBuilder() *CmdConfig {
+	return CommandBuilder("docs").
+		SetShortDescription("Generate documentation for all commands").
+		AddPersistentFlags(
+			func(flags *pflag.FlagSet) {
+				flags.String(DOCS_DIR_FLAG_NAME, DEFAULT_DOCS_DIR, "directory the documentation is written to")
+			},
+		).
+		AddSubCommands(
+			docsCommandBuilder(
+				"markdown", "Generate markdown documentation", func(root *cobra.Command, dir string) error {
+					return GenMarkdownTreeCustom(root, dir, emptyFilePrepender, identityLinkHandler)
+				},
+			),
+			docsCommandBuilder(
+				"man", "Generate man pages", func(root *cobra.Command, dir string) error {
+					state := CurrentState()
+					return GenManTreeCustom(
+						root, dir, &ManHeader{
+							Source: strings.TrimSpace(fmt.Sprintf("%s %s", state.AppName(), state.Version())),
+						},
+					)
+				},
+			),
+			docsCommandBuilder(
+				"rst", "Generate reStructuredText documentation", func(root *cobra.Command, dir string) error {
+					return GenReSTTreeCustom(root, dir, emptyFilePrepender, DefaultReSTLinkHandler)
+				},
+			),
+			docsCommandBuilder(
+				"yaml", "Generate a yaml command reference", func(root *cobra.Command, dir string) error {
+					return GenYamlTreeCustom(root, dir, emptyFilePrepender)
+				},
+			),
+			docsCommandBuilder(
+				"json", "Generate a json command reference", func(root *cobra.Command, dir string) error {
+					return GenJSONTreeCustom(root, dir, emptyFilePrepender)
+				},
+			),
+			docsCommandBuilder(
+				"single", "Generate the documentation as one markdown document", func(root *cobra.Command, dir string) error {
+					f, err := os.Create(filepath.Join(dir, strings.ReplaceAll(root.CommandPath(), " ", "_")+"_reference.md"))
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+					return GenSinglePageMarkdown(root, f)
+				},
+			),
+			docsCommandBuilder(
+				"html", "Generate a static html documentation site", func(root *cobra.Command, dir string) error {
+					return GenHTMLSite(root, dir)
+				},
+			),
+			CommandBuilder("verify").
+				SetShortDescription("Check that the markdown documentation is up to date").
+				SetArgValidations(cobra.NoArgs).
+				SetRun(
+					func(cmd *cobra.Command, args []string) {
+						dir, err := cmd.Flags().GetString(DOCS_DIR_FLAG_NAME)
+						CheckError(err, "Unable to read flag [%s]", DOCS_DIR_FLAG_NAME)
+						err = VerifyMarkdownTree(cmd.Root(), dir)
+						if drift, ok := err.(*DocDrift); ok {
+							CheckError(drift.Report(cmd.OutOrStdout()), "Error reporting documentation drift")
+						}
+						CurrentState().CheckError(false, err, "Documentation in [%s] needs to be regenerated", dir)
+					},
+				),
+		)
+}
+
+func docsCommandBuilder(use string, short string, gen func(root *cobra.Command, dir string) error) *CmdConfig {
+	return CommandBuilder(use).
+		SetShortDescription(short).
+		SetArgValidations(cobra.NoArgs).
+		SetRun(
+			func(cmd *cobra.Command, args []string) {
+				dir, err := cmd.Flags().GetString(DOCS_DIR_FLAG_NAME)
+				if err != nil {
+					CurrentState().CheckError(false, err, "Unable to read flag [%s]", DOCS_DIR_FLAG_NAME)
+					return
+				}
+				dir = CreateDir(dir).AbsFilePath()
+				CurrentState().CheckError(
+					false, gen(cmd.Root(), dir), "Error generating %s documentation in [%s]", use, dir,
+				)
+			},
+		)
+}
+
+func emptyFilePrepender(_ string) string {
+	return ""
+}
+
+func identityLinkHandler(_ string, link string) string {
+	return link
+}
@@ -0,0 +1,130 @@
+package golang_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStandardTestRoot() *cobra.Command {
+	return AttachStandardCommands(
+		CommandBuilder("suzy").
+			SetShortDescription("suzy does things").
+			AddSubCommands(CommandBuilder("q").SetShortDescription("suzy q").SetRun(func(*cobra.Command, []string) {})).
+			Build(),
+	)
+}
+
+func TestVersionCommandPrintsJson(t *testing.T) {
+	CurrentState().SetAppName("suzy").SetVersion("1.2.3").SetCommitSha("abc123")
+
+	out := new(bytes.Buffer)
+	root := newStandardTestRoot()
+	root.SetOut(out)
+	root.SetArgs([]string{"version", "--json"})
+	assert.NoError(t, root.Execute())
+
+	var info VersionInfo
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &info))
+	assert.Equal(t, "suzy", info.Name)
+	assert.Equal(t, "1.2.3", info.Version)
+	assert.Equal(t, "abc123", info.CommitSha)
+}
+
+func TestVersionCommandWritesToTheCommandOutput(t *testing.T) {
+	CurrentState().SetAppName("suzy").SetVersion("1.2.3").SetCommitSha("abc123")
+
+	out := new(bytes.Buffer)
+	root := newStandardTestRoot()
+	root.SetOut(out)
+	root.SetArgs([]string{"version"})
+	assert.NoError(t, root.Execute())
+	assert.Contains(t, out.String(), "Name: suzy\nVersion: 1.2.3\nCommit SHA: abc123\n")
+}
+
+func TestProfileFlagIsOnlyOfferedByTheConfigCommands(t *testing.T) {
+	root := newStandardTestRoot()
+	q, _, err := root.Find([]string{"q"})
+	assert.NoError(t, err)
+	assert.Nil(t, q.Flag(PROFILE_FLAG_NAME), "commands that ignore the profile shouldn't offer it")
+
+	show, _, err := root.Find([]string{"config", "show"})
+	assert.NoError(t, err)
+	assert.NotNil(t, show.Flag(PROFILE_FLAG_NAME))
+}
+
+func TestDocsCommandsGenerateEveryPage(t *testing.T) {
+	tests := map[string][]string{
+		"markdown": {"suzy.md", "suzy_q.md", "suzy_docs_man.md"},
+		"man":      {"suzy.1", "suzy-q.1", "suzy-docs-man.1"},
+		"rst":      {"suzy.rst", "suzy_q.rst", "suzy_docs_man.rst"},
+		"yaml":     {"suzy.yaml", "suzy_q.yaml", "suzy_docs_man.yaml"},
+		"json":     {"suzy.json", "suzy_q.json", "suzy_docs_man.json"},
+		"single":   {"suzy_reference.md"},
+		"html":     {"index.html", "suzy.html", "suzy_q.html", "suzy_docs_man.html"},
+	}
+
+	for format, expectedFiles := range tests {
+		dir := t.TempDir()
+		root := newStandardTestRoot()
+		root.SetArgs([]string{"docs", format, "--dir", dir})
+		assert.NoError(t, root.Execute(), "docs %s should succeed", format)
+
+		for _, expected := range expectedFiles {
+			_, err := os.Stat(filepath.Join(dir, expected))
+			assert.NoError(t, err, "docs %s should have written %s", format, expected)
+		}
+	}
+}
+
+func TestDocsCommandsFailWhenGenerationFails(t *testing.T) {
+	notADir := filepath.Join(t.TempDir(), "docs")
+	assert.NoError(t, os.WriteFile(notADir, nil, 0644))
+
+	result := NewCmdHarness(newStandardTestRoot).Run("docs", "markdown", "--dir", notADir)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Error(t, result.Err)
+}
+
+func TestDocsVerifyReportsDrift(t *testing.T) {
+	dir := t.TempDir()
+	root := newStandardTestRoot()
+	root.SetArgs([]string{"docs", "markdown", "--dir", dir})
+	assert.NoError(t, root.Execute())
+
+	result := NewCmdHarness(newStandardTestRoot).Run("docs", "verify", "--dir", dir)
+	assert.Equal(t, 0, result.ExitCode, result.Stdout)
+	assert.NoError(t, result.Err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "suzy_q.md"), []byte("stale\n"), 0644))
+	result = NewCmdHarness(newStandardTestRoot).Run("docs", "verify", "--dir", dir)
+	assert.Equal(t, 1, result.ExitCode)
+	var drift *DocDrift
+	assert.ErrorAs(t, result.Err, &drift, "Execute should return the drift")
+	assert.Contains(t, result.Stdout, "Changed: suzy_q.md")
+	assert.Contains(t, result.Stdout, "-stale\n+## suzy q\n")
+}
+
+func TestConfigCommandsWriteToTheCommandOutput(t *testing.T) {
+	state := CurrentState()
+	previous := state.config
+	defer func() { state.config = previous }()
+	state.config = newBindTestConfig(t, "name: suzy\ncolors:\n  fav: red\n")
+
+	for args, expected := range map[string]string{
+		"config show": "name: suzy", "config get colors": "fav: red", "config get name": "suzy",
+	} {
+		out := new(bytes.Buffer)
+		root := newStandardTestRoot()
+		root.SetOut(out)
+		root.SetArgs(strings.Fields(args))
+		assert.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), expected, args)
+	}
+}
@@ -33,6 +33,8 @@ package golang_utils
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"text/template"
 )
 
 const (
@@ -42,8 +44,30 @@ const (
 	OR_DEFAULT_FUNC_NAME = "orDefault"
 	OR_EMPTY_FUNC_NAME   = "orEmpty"
 	TERNARY_FUNC_NAME    = "ternary"
+	JOIN_FUNC_NAME       = "join"
+	REPLACE_FUNC_NAME    = "replace"
+	UPPER_FUNC_NAME      = "upper"
+	LOWER_FUNC_NAME      = "lower"
+	TRIM_FUNC_NAME       = "trim"
 )
 
+// TemplateFuncs returns the functions in this file keyed by their template names along with a few string helpers.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		QUOTE_FUNC_NAME:      Quote,
+		EMPTY_FUNC_NAME:      Empty,
+		NOT_EMPTY_FUNC_NAME:  NotEmpty,
+		OR_DEFAULT_FUNC_NAME: OrDefault,
+		OR_EMPTY_FUNC_NAME:   OrEmpty,
+		TERNARY_FUNC_NAME:    Ternary,
+		JOIN_FUNC_NAME:       func(sep string, values []string) string { return strings.Join(values, sep) },
+		REPLACE_FUNC_NAME:    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		UPPER_FUNC_NAME:      strings.ToUpper,
+		LOWER_FUNC_NAME:      strings.ToLower,
+		TRIM_FUNC_NAME:       strings.TrimSpace,
+	}
+}
+
 func Quote(input any) string {
 	switch val := input.(type) {
 	case string: